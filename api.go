@@ -4,15 +4,28 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
-	"net/url"
 	"slices"
 	"strconv"
 	"strings"
 	"time"
 )
 
-func fetchCategories() ([]category, error) {
+// tencentProvider 腾讯体育数据源
+type tencentProvider struct {
+	client      *apiClient
+	matchWebURL string // matchweb.sports.qq.com
+	appURL      string // app.sports.qq.com
+}
+
+func newTencentProvider(client *apiClient, matchWebURL, appURL string) tencentProvider {
+	return tencentProvider{
+		client:      client,
+		matchWebURL: strings.TrimSuffix(matchWebURL, "/"),
+		appURL:      strings.TrimSuffix(appURL, "/"),
+	}
+}
+
+func (p tencentProvider) fetchCategories(ctx context.Context) ([]category, error) {
 	var resp struct {
 		Code int    `json:"code"`
 		Msg  string `json:"msg"`
@@ -23,8 +36,9 @@ func fetchCategories() ([]category, error) {
 		} `json:"data"`
 	}
 
-	err := request(
-		"https://matchweb.sports.qq.com/matchUnion/cateColumns",
+	err := p.client.request(
+		ctx,
+		p.matchWebURL+"/matchUnion/cateColumns",
 		nil,
 		&resp,
 	)
@@ -33,8 +47,7 @@ func fetchCategories() ([]category, error) {
 	}
 
 	if resp.Code != 0 {
-		return nil, fmt.Errorf("fetch categories failed, code: %d, msg: %s",
-			resp.Code, resp.Msg)
+		return nil, &apiError{api: "categories", code: resp.Code, msg: resp.Msg}
 	}
 
 	categories := []category{hotCategory}
@@ -44,23 +57,27 @@ func fetchCategories() ([]category, error) {
 	return categories, nil
 }
 
-func fetchSchedule(categoyID string) ([]match, error) {
+// fetchSchedule 获取start到end之间的赛程，包含这两天
+func (p tencentProvider) fetchSchedule(
+	ctx context.Context,
+	categoyID string,
+	start, end time.Time,
+) ([]match, error) {
 	var resp struct {
 		Code int                `json:"code"`
 		Msg  string             `json:"msg"`
 		Data map[string][]match `json:"data"`
 	}
 
-	start := time.Now()
-	end := start.AddDate(0, 0, 5) //nolint:mnd // 获取5天的赛程
-	p := map[string]string{
+	params := map[string]string{
 		"columnId":  categoyID,
 		"startTime": start.Format("2006-01-02"),
 		"endTime":   end.Format("2006-01-02"),
 	}
-	err := request(
-		"https://matchweb.sports.qq.com/matchUnion/list",
-		p,
+	err := p.client.request(
+		ctx,
+		p.matchWebURL+"/matchUnion/list",
+		params,
 		&resp,
 	)
 	if err != nil {
@@ -68,8 +85,7 @@ func fetchSchedule(categoyID string) ([]match, error) {
 	}
 
 	if resp.Code != 0 {
-		return nil, fmt.Errorf("fetch schedule failed, code: %d, msg: %s",
-			resp.Code, resp.Msg)
+		return nil, &apiError{api: "schedule", code: resp.Code, msg: resp.Msg}
 	}
 
 	return sortMatches(resp.Data)
@@ -109,30 +125,7 @@ func sortMatches(data map[string][]match) ([]match, error) {
 	return matches, nil
 }
 
-func fetchTextLives(matchID string) ([]textLive, error) {
-	indexs, err := fetchTextLiveIndexes(matchID)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(indexs) == 0 {
-		return nil, nil
-	}
-
-	if len(indexs) > cfg.textLiveCount {
-		indexs = indexs[:cfg.textLiveCount]
-	}
-
-	ret, err := fetchIndexTexts(matchID, indexs)
-	if err != nil {
-		return nil, err
-	}
-
-	var textLives []textLive
-	for _, index := range indexs {
-		textLives = append(textLives, ret[index])
-	}
-
+func sortTextLives(textLives []textLive) {
 	slices.SortStableFunc(textLives, func(a, b textLive) int {
 		indexA := strings.Split(a.IndexValue, "_")
 		indexB := strings.Split(b.IndexValue, "_")
@@ -146,11 +139,9 @@ func fetchTextLives(matchID string) ([]textLive, error) {
 		}
 		return retA - retB
 	})
-
-	return textLives, nil
 }
 
-func fetchMatchHasTextLives(matchID string) (bool, error) {
+func (p tencentProvider) fetchMatchHasTextLives(ctx context.Context, matchID string) (bool, error) {
 	var resp struct {
 		Code int    `json:"code"`
 		Msg  string `json:"msg"`
@@ -159,8 +150,9 @@ func fetchMatchHasTextLives(matchID string) (bool, error) {
 		} `json:"data"`
 	}
 
-	err := request(
-		"https://matchweb.sports.qq.com/kbs/matchDetail",
+	err := p.client.request(
+		ctx,
+		p.matchWebURL+"/kbs/matchDetail",
 		map[string]string{"mid": matchID},
 		&resp,
 	)
@@ -169,14 +161,29 @@ func fetchMatchHasTextLives(matchID string) (bool, error) {
 	}
 
 	if resp.Code != 0 {
-		return false, fmt.Errorf("fetch match has text live sfailed, code: %d, msg: %s",
-			resp.Code, resp.Msg)
+		return false, &apiError{api: "match has text lives", code: resp.Code, msg: resp.Msg}
 	}
 
 	return resp.Data.IsHasTextLive, nil
 }
 
-func fetchTextLiveIndexes(matchID string) ([]string, error) {
+// fetchTextLives 先获取所有文字直播的index，再只请求需要的index的详情
+func (p tencentProvider) fetchTextLives(ctx context.Context, matchID string, q textLiveQuery) (textLivePage, error) {
+	indexes, err := p.fetchTextLiveIndexes(ctx, matchID)
+	if err != nil {
+		return textLivePage{}, err
+	}
+
+	var texts map[string]textLive
+	if wanted := q.pick(indexes); len(wanted) > 0 {
+		if texts, err = p.fetchIndexTexts(ctx, matchID, wanted); err != nil {
+			return textLivePage{}, err
+		}
+	}
+	return newTextLivePage(indexes, texts, q), nil
+}
+
+func (p tencentProvider) fetchTextLiveIndexes(ctx context.Context, matchID string) ([]string, error) {
 	var resp struct {
 		Code int    `json:"code"`
 		Msg  string `json:"msg"`
@@ -188,8 +195,9 @@ func fetchTextLiveIndexes(matchID string) ([]string, error) {
 		} `json:"data"`
 	}
 
-	err := request(
-		"https://app.sports.qq.com/textLive/index",
+	err := p.client.request(
+		ctx,
+		p.appURL+"/textLive/index",
 		map[string]string{"mid": matchID},
 		&resp,
 	)
@@ -198,8 +206,7 @@ func fetchTextLiveIndexes(matchID string) ([]string, error) {
 	}
 
 	if resp.Code != 0 {
-		return nil, fmt.Errorf("fetch text live index failed, code: %d, msg: %s",
-			resp.Code, resp.Msg)
+		return nil, &apiError{api: "text live index", code: resp.Code, msg: resp.Msg}
 	}
 
 	if len(resp.Data.Tabs) == 0 {
@@ -209,7 +216,11 @@ func fetchTextLiveIndexes(matchID string) ([]string, error) {
 	return resp.Data.Tabs[0].Index, nil
 }
 
-func fetchIndexTexts(matchID string, indexes []string) (map[string]textLive, error) {
+func (p tencentProvider) fetchIndexTexts(
+	ctx context.Context,
+	matchID string,
+	indexes []string,
+) (map[string]textLive, error) {
 	ids := strings.Split(matchID, ":")
 	if len(ids) != 2 { //nolint:mnd // 分割competitionId和matchId
 		return nil, fmt.Errorf("invalid match id: %s", matchID)
@@ -217,33 +228,32 @@ func fetchIndexTexts(matchID string, indexes []string) (map[string]textLive, err
 
 	var resp []json.RawMessage
 
-	p := map[string]string{
+	params := map[string]string{
 		"competitionId": ids[0],
 		"matchId":       ids[1],
 		"ids":           strings.Join(indexes, ","),
 	}
-	err := request(
-		"https://matchweb.sports.qq.com/textLive/detail",
-		p,
-		&resp,
-	)
+	u := p.matchWebURL + "/textLive/detail"
+	err := p.client.requestUncached(ctx, u, params, &resp)
 	if err != nil {
-		return nil, err
+		return cachedTextLives(ctx, matchID, indexes, err)
 	}
 
 	if len(resp) != 3 { //nolint:mnd // 返回值是三个元素的slice
-		return nil, fmt.Errorf("fetch text live failed, resp: %v", resp)
+		return nil, &decodeError{url: u, err: fmt.Errorf("unexpected text live resp: %v", resp)}
 	}
 
 	var ret map[string]textLive
 	if err = json.Unmarshal(resp[1], &ret); err != nil {
-		return nil, err
+		return nil, &decodeError{url: u, err: err}
 	}
 
+	// 保存失败不影响请求的结果
+	_ = cacheTextLives(matchID, ret)
 	return ret, nil
 }
 
-func fetchStats(matchID string) (*stats, error) {
+func (p tencentProvider) fetchStats(ctx context.Context, matchID string) (*stats, error) {
 	var resp struct {
 		Code int    `json:"code"`
 		Msg  string `json:"msg"`
@@ -259,23 +269,19 @@ func fetchStats(matchID string) (*stats, error) {
 		} `json:"data"`
 	}
 
-	err := request(
-		"https://app.sports.qq.com/match/statDetail",
-		map[string]string{"mid": matchID},
-		&resp,
-	)
+	u := p.appURL + "/match/statDetail"
+	err := p.client.request(ctx, u, map[string]string{"mid": matchID}, &resp)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.Code != 0 {
-		return nil, fmt.Errorf("fetch stats failed, code: %d, msg: %s",
-			resp.Code, resp.Msg)
+		return nil, &apiError{api: "stats", code: resp.Code, msg: resp.Msg}
 	}
 
 	var g *goalStats
 	var t []teamStats
-	var p []playerStats
+	var players []playerStats
 	for _, v := range resp.Data.Stats {
 		switch v.Type {
 		case "12":
@@ -285,9 +291,9 @@ func fetchStats(matchID string) (*stats, error) {
 		case "14", "102": // 14：篮球 102：足球
 			t = v.TeamStats
 		case "15":
-			err = json.Unmarshal(v.PlayerStats, &p)
+			err = json.Unmarshal(v.PlayerStats, &players)
 			if err != nil {
-				return nil, err
+				return nil, &decodeError{url: u, err: err}
 			}
 		}
 	}
@@ -297,7 +303,7 @@ func fetchStats(matchID string) (*stats, error) {
 		goal:        g,
 		teamStats:   t,
 		livePeriod:  resp.Data.LivePeriod,
-		playerStats: splitPlayerStats(p),
+		playerStats: splitPlayerStats(players),
 	}, nil
 }
 
@@ -324,40 +330,3 @@ func splitPlayerStats(s []playerStats) [][]playerStats {
 
 	return teams
 }
-
-func request(u string, p map[string]string, ret any) error {
-	ctx, cancel := context.WithTimeout(
-		context.Background(),
-		cfg.apiRequestTimeout,
-	)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
-	if err != nil {
-		return err
-	}
-
-	q := url.Values{}
-	for k, v := range p {
-		q.Add(k, v)
-	}
-
-	req.Header.Add(
-		"User-Agent",
-		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/111.0.0.0 Safari/537.36",
-	)
-	req.URL.RawQuery = q.Encode()
-
-	hresp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return err
-	}
-
-	defer hresp.Body.Close()
-
-	if hresp.StatusCode != http.StatusOK {
-		return fmt.Errorf("request %s failed: %d", u, hresp.StatusCode)
-	}
-
-	return json.NewDecoder(hresp.Body).Decode(&ret)
-}
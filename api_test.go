@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixtureProvider 回放testdata/replay中的响应，当前时间为录制时的时间
+func fixtureProvider(t *testing.T) provider {
+	t.Helper()
+
+	c := cfg
+	c.replayDir = "testdata/replay"
+	c.cacheDir = ""
+	useConfig(t, c)
+
+	prev := currentTime
+	t.Cleanup(func() { currentTime = prev })
+	if err := setupFixtureClock(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	return newTencentProvider(newAPIClient(), cfg.matchWebBaseURL, cfg.appBaseURL)
+}
+
+func TestReplaySchedule(t *testing.T) {
+	p := fixtureProvider(t)
+
+	if got := currentTime().Format(time.DateOnly); got != "2025-01-02" {
+		t.Fatalf("currentTime() = %s, want the recorded date 2025-01-02", got)
+	}
+
+	msg, ok := scheduleFetcher(p, nil, category{ID: "100000"}, 0)(context.Background()).(scheduleMsg)
+	if !ok {
+		t.Fatal("scheduleFetcher did not return a scheduleMsg")
+	}
+	if msg.err != nil {
+		t.Fatal(msg.err)
+	}
+
+	// 按日期排序，去掉发布会和日期无效的分组
+	var mids []string
+	for _, m := range msg.matches {
+		mids = append(mids, m.MID)
+	}
+	want := []string{"100000:1471545", "100000:1471546"}
+	if !slices.Equal(mids, want) {
+		t.Errorf("matches = %v, want %v", mids, want)
+	}
+}
+
+func TestReplayTextLives(t *testing.T) {
+	p := fixtureProvider(t)
+
+	textLives, err := newTextLiveFeed(p, "100000:1471545").update(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 和index列表的顺序相同，最新的在前面，按index的数字排序而不是按字符串
+	var indexes []string
+	for _, v := range textLives {
+		indexes = append(indexes, v.IndexValue)
+	}
+	want := []string{"9_1471545", "10_1471545", "11_1471545"}
+	if !slices.Equal(indexes, want) {
+		t.Fatalf("text lives = %v, want %v", indexes, want)
+	}
+
+	latest := textLives[0]
+	if latest.LeftGoal != "3" || latest.RightGoal != "2" || latest.Time != "11:05" {
+		t.Errorf("textLives[0] = %+v, want the latest entry", latest)
+	}
+	if oldest := textLives[len(textLives)-1]; oldest.Content != "比赛开始" {
+		t.Errorf("last text live = %+v, want the first entry of the match", oldest)
+	}
+}
+
+func TestReplayStats(t *testing.T) {
+	p := fixtureProvider(t)
+
+	s, err := p.fetchStats(context.Background(), "100000:1471545")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.goal == nil || len(s.goal.Rows) != 2 {
+		t.Errorf("goal = %v, want 2 rows", s.goal)
+	}
+	if len(s.teamStats) != 1 {
+		t.Errorf("team stats = %v, want 1 row", s.teamStats)
+	}
+
+	// 每个表头开始一只球队，空行被跳过
+	var sizes []int
+	for _, players := range s.playerStats {
+		sizes = append(sizes, len(players))
+	}
+	if want := []int{3, 2}; !slices.Equal(sizes, want) {
+		t.Errorf("player stats sizes = %v, want %v", sizes, want)
+	}
+}
+
+func TestSplitPlayerStats(t *testing.T) {
+	head := playerStats{Head: []string{"球员", "得分"}}
+	row := playerStats{Row: []string{"塔图姆", "32"}}
+
+	tests := []struct {
+		name string
+		in   []playerStats
+		want []int
+	}{
+		{"empty", nil, nil},
+		{"one team", []playerStats{head, row, row}, []int{3}},
+		{"two teams", []playerStats{head, row, head, row, row}, []int{2, 3}},
+		{"skip blank", []playerStats{head, {}, row, {}, head}, []int{2, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sizes []int
+			for _, players := range splitPlayerStats(tt.in) {
+				sizes = append(sizes, len(players))
+			}
+			if !slices.Equal(sizes, tt.want) {
+				t.Errorf("sizes = %v, want %v", sizes, tt.want)
+			}
+		})
+	}
+}
+
+func TestTencentFetchTextLives(t *testing.T) {
+	c := cfg
+	c.textLiveCount = 2
+	c.cacheDir = ""
+	useConfig(t, c)
+
+	var requested []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/textLive/index":
+			_, _ = w.Write([]byte(`{"code":0,"data":{"tabs":[{"index":["9_1","10_1","11_1","12_1"]}]}}`))
+		case "/textLive/detail":
+			ids := r.URL.Query().Get("ids")
+			requested = append(requested, ids)
+			texts := map[string]textLive{}
+			for _, id := range strings.Split(ids, ",") {
+				if id != "11_1" {
+					texts[id] = textLive{IndexValue: id}
+				}
+			}
+			_ = json.NewEncoder(w).Encode([]any{0, texts, ""})
+		}
+	}))
+	t.Cleanup(srv.Close)
+	p := newTencentProvider(newAPIClient(), srv.URL, srv.URL)
+
+	tests := []struct {
+		name          string
+		q             textLiveQuery
+		wantRequested string // 为空时不请求详情
+		wantTexts     []string
+		wantMissing   []string
+	}{
+		{"latest", textLiveQuery{}, "9_1,10_1", []string{"10_1", "9_1"}, nil},
+		{"latest all known", textLiveQuery{known: map[string]bool{"9_1": true, "10_1": true}}, "", nil, nil},
+		{
+			name:          "older",
+			q:             textLiveQuery{known: map[string]bool{"9_1": true, "10_1": true}, older: true},
+			wantRequested: "11_1,12_1",
+			wantTexts:     []string{"12_1"},
+			wantMissing:   []string{"11_1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requested = nil
+			page, err := p.fetchTextLives(context.Background(), "100000:1", tt.q)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var wantRequested []string
+			if tt.wantRequested != "" {
+				wantRequested = []string{tt.wantRequested}
+			}
+			if !slices.Equal(requested, wantRequested) {
+				t.Errorf("requested = %q, want %q", requested, wantRequested)
+			}
+			if !slices.Equal(page.ids, []string{"9_1", "10_1", "11_1", "12_1"}) {
+				t.Errorf("ids = %v", page.ids)
+			}
+			texts := slices.Sorted(maps.Keys(page.textLives))
+			if !slices.Equal(texts, tt.wantTexts) {
+				t.Errorf("text lives = %v, want %v", texts, tt.wantTexts)
+			}
+			if !slices.Equal(page.missing, tt.wantMissing) {
+				t.Errorf("missing = %v, want %v", page.missing, tt.wantMissing)
+			}
+		})
+	}
+}
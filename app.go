@@ -1,6 +1,12 @@
 package main
 
 import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -13,30 +19,52 @@ const (
 )
 
 type app struct {
+	provider        provider
+	favorites       *favorites
+	notifier        *notifier
+	poller          *poller
+	textLiveFeed    *textLiveFeed
 	categoryPanel   categoryPanel
 	schedulePanel   schedulePanel
 	textLivePanel   textLivePanel
 	statsPanel      statsPanel
 	focus           focus
+	debug           bool
+	toast           toast
+	replay          *replayClock // 回放时的虚拟时钟，不是回放时为nil
+	width           int
 	availableHeight int
+	textLiveX       int // 文字直播面板的起始列
 }
 
-func newApp() app {
+func newApp(p provider, favs *favorites) app {
 	return app{
-		categoryPanel: newCategoryPanel(),
-		schedulePanel: newSchedulePanel(),
+		provider:      p,
+		favorites:     favs,
+		notifier:      newNotifier(),
+		poller:        newPoller(),
+		categoryPanel: newCategoryPanel(p, favs),
+		schedulePanel: newSchedulePanel(favs),
 		textLivePanel: newTextLivePanel(textLivePanelWidth),
 		statsPanel:    newStatsPanel(),
 		focus:         focusCategory,
 	}
 }
 
+// newReplayApp 回放录制的比赛，数据按clock的虚拟时间更新
+func newReplayApp(p provider, clock *replayClock) app {
+	a := newApp(p, &favorites{})
+	a.replay = clock
+	return a
+}
+
 func (a app) Init() tea.Cmd {
 	return tea.Batch(
 		tea.SetWindowTitle("SportX"),
 		a.categoryPanel.Init(),
 		a.schedulePanel.Init(),
 		a.textLivePanel.Init(),
+		a.replayTick(),
 	)
 }
 
@@ -50,36 +78,102 @@ func (a app) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a, cmd
 	case categorySelectionMsg:
 		a.schedulePanel, cmd = a.schedulePanel.Update(msg)
-		return a, cmd
+		cmds = append(cmds, cmd)
+		c := category(msg)
+		a.notifier.reset()
+		cmd = a.poller.subscribe(scheduleKey(c), cfg.scheduleRefreshInterval, scheduleFetcher(a.provider, a.favorites, c, 0))
+		cmds = append(cmds, cmd)
+		return a, tea.Batch(cmds...)
+	case scheduleWindowMsg:
+		a.notifier.reset()
+		return a, a.poller.subscribe(scheduleKey(msg.category), cfg.scheduleRefreshInterval,
+			scheduleFetcher(a.provider, a.favorites, msg.category, msg.offset))
 	case scheduleMsg:
 		a.schedulePanel, cmd = a.schedulePanel.Update(msg)
-		return a, cmd
+		cmds = append(cmds, cmd, a.notifyChanges(msg))
+		// 只有请求的结果才结束这次请求
+		if msg.isFinished() {
+			cmds = append(cmds, a.poller.done(scheduleKey(msg.category), a.schedulePanel.shouldRefresh(msg)))
+		}
+		return a, tea.Batch(cmds...)
 	case matchSelectionMsg:
+		return a.onMatchSelectionMsg(msg)
+	case textLivesMsg:
 		a.textLivePanel, cmd = a.textLivePanel.Update(msg)
 		cmds = append(cmds, cmd)
+		if msg.isFinished() {
+			cmds = append(cmds, a.poller.done(textLivesKey(msg.matchID), a.shouldRefreshTextLives(msg)))
+		}
+		return a, tea.Batch(cmds...)
+	case statsMsg:
 		a.statsPanel, cmd = a.statsPanel.Update(msg)
 		cmds = append(cmds, cmd)
+		if msg.isFinished() {
+			cmds = append(cmds, a.poller.done(statsKey(msg.matchID), a.statsPanel.shouldRefresh(msg)))
+		}
 		return a, tea.Batch(cmds...)
-	case textLivesMsg:
+	case loadOlderTextLivesMsg:
+		if a.textLiveFeed == nil || a.textLiveFeed.matchID != string(msg) {
+			return a, nil
+		}
+		return a, a.poller.once(textLivesKey(string(msg)), olderTextLivesFetcher(a.textLiveFeed))
+	case olderTextLivesMsg:
 		a.textLivePanel, cmd = a.textLivePanel.Update(msg)
 		return a, cmd
-	case statsMsg:
-		a.statsPanel, cmd = a.statsPanel.Update(msg)
+	case list.FilterMatchesMsg:
+		// 只有赛程面板可以搜索，切换面板后也要更新搜索结果
+		a.schedulePanel, cmd = a.schedulePanel.Update(msg)
 		return a, cmd
+	case pollMsg:
+		return a, a.poller.poll(msg)
+	case exportTranscriptMsg:
+		cmd = a.exportTranscript(msg)
+		return a, cmd
+	case transcriptExportedMsg:
+		if msg.err != nil {
+			return a, a.toast.show("导出失败: " + msg.err.Error())
+		}
+		return a, a.toast.show("已导出到 " + msg.path)
+	case favoritesChangedMsg:
+		return a.onFavoritesChangedMsg(msg)
+	case notificationFailedMsg:
+		return a, a.toast.show("通知失败: " + msg.err.Error())
+	case toastExpiredMsg:
+		a.toast.expire(msg)
+		return a, nil
+	case replayTickMsg:
+		return a, a.replayTick()
+	case tea.MouseMsg:
+		// 鼠标在文字直播面板上时滚动文字直播
+		if msg.X >= a.textLiveX {
+			a.textLivePanel, cmd = a.textLivePanel.Update(msg)
+			return a, cmd
+		}
 	case tea.WindowSizeMsg:
 		a.onWindowSizeMsg(msg)
 		return a, nil
 	case spinner.TickMsg:
 		return a.onSpinnerTickMsg(msg)
 	case tea.KeyMsg:
-		switch msg.String() {
-		case tea.KeyTab.String():
+		if a.focus == focusSchedule && a.schedulePanel.list.SettingFilter() {
+			break
+		}
+		if a.replay != nil {
+			if cmd, ok := a.onReplayKey(msg); ok {
+				return a, cmd
+			}
+		}
+		switch {
+		case key.Matches(msg, cfg.keys.nextPanel):
 			a.focus = a.focus.next()
 			return a, nil
-		case tea.KeyShiftTab.String():
+		case key.Matches(msg, cfg.keys.prevPanel):
 			a.focus = a.focus.prev()
 			return a, nil
-		case "ctrl+c", "q":
+		case key.Matches(msg, cfg.keys.debug):
+			a.debug = !a.debug
+			return a, nil
+		case key.Matches(msg, cfg.keys.quit):
 			return a, tea.Quit
 		}
 	}
@@ -94,18 +188,202 @@ func (a app) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case focusStats:
 		a.statsPanel, cmd = a.statsPanel.Update(msg)
 		return a, cmd
+	case focusTextLive:
+		a.textLivePanel, cmd = a.textLivePanel.Update(msg)
+		return a, cmd
 	}
 
 	return a, nil
 }
 
 func (a app) View() string {
-	return lipgloss.JoinHorizontal(lipgloss.Left,
+	textLiveView := a.textLivePanel.View(a.focus == focusTextLive)
+	if a.debug {
+		textLiveView = a.debugView()
+	}
+
+	view := lipgloss.JoinHorizontal(lipgloss.Left,
 		a.categoryPanel.View(a.focus == focusCategory),
 		a.schedulePanel.View(a.focus == focusSchedule),
 		a.statsPanel.View(a.focus == focusStats),
-		a.textLivePanel.View(),
+		textLiveView,
+	)
+	if a.replay != nil {
+		view = lipgloss.JoinVertical(lipgloss.Left, view, a.replayView())
+	}
+	return a.toast.overlay(view, a.width)
+}
+
+// debugView 显示所有的轮询订阅
+func (a app) debugView() string {
+	content := "轮询订阅\n\n" + strings.Join(a.poller.subscriptions(), "\n")
+	return borderStyle.
+		Width(textLivePanelWidth).
+		Height(a.availableHeight).
+		Padding(0, 1).
+		Render(content)
+}
+
+// onFavoritesChangedMsg 显示提示，正在看我的关注时重新获取赛程
+func (a app) onFavoritesChangedMsg(msg favoritesChangedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		return a, a.toast.show("保存关注失败: " + msg.err.Error())
+	}
+
+	cmd := a.toast.show(msg.text)
+	c := a.schedulePanel.category
+	if !c.equal(favoriteCategory) {
+		return a, cmd
+	}
+	return a, tea.Batch(
+		cmd,
+		a.poller.subscribe(scheduleKey(c), cfg.scheduleRefreshInterval,
+			scheduleFetcher(a.provider, a.favorites, c, a.schedulePanel.offset)),
+	)
+}
+
+// replayTickMsg 回放时每秒刷新一次界面上的时间
+type replayTickMsg struct{}
+
+func (a app) replayTick() tea.Cmd {
+	if a.replay == nil {
+		return nil
+	}
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return replayTickMsg{}
+	})
+}
+
+// onReplayKey 处理回放的快捷键，改变时间后立即刷新所有数据
+func (a app) onReplayKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch {
+	case key.Matches(msg, cfg.keys.replayPause):
+		a.replay.togglePause()
+		return nil, true
+	case key.Matches(msg, cfg.keys.replayFaster):
+		a.replay.changeSpeed(1)
+		return nil, true
+	case key.Matches(msg, cfg.keys.replaySlower):
+		a.replay.changeSpeed(-1)
+		return nil, true
+	case key.Matches(msg, cfg.keys.replayForward):
+		a.replay.seek(replaySeekStep)
+	case key.Matches(msg, cfg.keys.replayBackward):
+		a.replay.seek(-replaySeekStep)
+	case key.Matches(msg, cfg.keys.replayStep):
+		a.replay.step()
+	default:
+		return nil, false
+	}
+	return a.poller.refresh(), true
+}
+
+// replayView 回放的状态栏
+func (a app) replayView() string {
+	help := "空格 暂停  < > 倍速  [ ] 快退/快进  . 单步"
+	content := "回放 " + a.replay.String() + "  " + hintStyle.Render(help)
+	return lipgloss.NewStyle().Width(a.width).MaxWidth(a.width).Padding(0, 1).Render(content)
+}
+
+func (a app) onMatchSelectionMsg(msg matchSelectionMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	var cmds []tea.Cmd
+
+	a.textLivePanel, cmd = a.textLivePanel.Update(msg)
+	cmds = append(cmds, cmd)
+	a.statsPanel, cmd = a.statsPanel.Update(msg)
+	cmds = append(cmds, cmd)
+
+	matchID := string(msg)
+	if matchID == "" {
+		a.textLiveFeed = nil
+		a.poller.unsubscribe(resourceTextLives)
+		a.poller.unsubscribe(resourceStats)
+		return a, tea.Batch(cmds...)
+	}
+
+	a.textLiveFeed = newTextLiveFeed(a.provider, matchID)
+	if m := a.schedulePanel.selectedMatch; m != nil && m.MID == matchID {
+		a.textLiveFeed.ended = m.MatchPeriod == periodEnd
+	}
+	cmd = a.poller.subscribe(
+		textLivesKey(matchID),
+		cfg.textLiveRefreshInterval,
+		textLivesFetcher(a.provider, a.textLiveFeed),
 	)
+	cmds = append(cmds, cmd)
+	cmd = a.poller.subscribe(statsKey(matchID), cfg.statsRefreshInterval, statsFetcher(a.provider, matchID))
+	cmds = append(cmds, cmd)
+
+	return a, tea.Batch(cmds...)
+}
+
+// notifyChanges 当前赛程中关注的球队和选中的比赛比分或者状态变化时通知，没有显示的赛程不会轮询
+func (a *app) notifyChanges(msg scheduleMsg) tea.Cmd {
+	// 回放的是已经发生过的变化
+	if a.replay != nil || !msg.isSuccess() {
+		return nil
+	}
+	// 切换分类或者日期范围之前发出的请求
+	s := a.schedulePanel
+	if !s.category.equal(msg.category) || s.offset != msg.offset {
+		return nil
+	}
+
+	selected := s.selectedMatch
+	changes := a.notifier.update(msg.matches, func(m match) bool {
+		if selected != nil && selected.MID == m.MID {
+			return true
+		}
+		return a.favorites.isTeam(m, m.LeftName) || a.favorites.isTeam(m, m.RightName)
+	})
+	if len(changes) == 0 {
+		return nil
+	}
+
+	text := strings.Join(changes, "；")
+	return tea.Batch(a.toast.show(text), notify(text))
+}
+
+// shouldRefreshTextLives 已经结束的比赛获取一次之后不再刷新，更早的内容在滚动时加载
+func (a app) shouldRefreshTextLives(msg textLivesMsg) bool {
+	if !msg.hasData {
+		return false
+	}
+	if msg.isFailed() {
+		return true
+	}
+	ended := a.textLiveFeed != nil && a.textLiveFeed.matchID == msg.matchID && a.textLiveFeed.ended
+	return msg.isSuccess() && !ended
+}
+
+// exportTranscript 在后台把文字直播面板中的比赛的文字直播、比分和统计写入文件
+func (a *app) exportTranscript(msg exportTranscriptMsg) tea.Cmd {
+	if a.textLivePanel.matchID != msg.matchID {
+		return a.toast.show("导出失败: 比赛已切换")
+	}
+	// 使用赛程中最新的比分，列表可能被搜索或状态过滤掉了这场比赛，按ID在全部比赛中查找
+	matches := a.schedulePanel.msg.matches
+	i := slices.IndexFunc(matches, func(m match) bool { return m.MID == msg.matchID })
+	if i < 0 {
+		return a.toast.show("导出失败: 比赛不在当前的赛程中")
+	}
+	selected := matches[i]
+
+	t := transcript{
+		match:      selected,
+		textLives:  a.textLivePanel.msg.textLives,
+		exportedAt: time.Now(),
+	}
+	if a.statsPanel.msg.isSuccess() && a.statsPanel.msg.matchID == msg.matchID {
+		t.stats = a.statsPanel.msg.stats
+	}
+
+	dir := cfg.exportDir
+	return func() tea.Msg {
+		path, err := exportTranscript(dir, t, msg.format)
+		return transcriptExportedMsg{path: path, err: err}
+	}
 }
 
 func (a app) onSpinnerTickMsg(msg spinner.TickMsg) (tea.Model, tea.Cmd) {
@@ -126,17 +404,22 @@ func (a app) onSpinnerTickMsg(msg spinner.TickMsg) (tea.Model, tea.Cmd) {
 
 func (a *app) onWindowSizeMsg(msg tea.WindowSizeMsg) {
 	statsWidth := msg.Width - 4*borderStyle.GetHorizontalBorderSize() - categoryPanelWidth - schedulePanelWidth - textLivePanelWidth
+	a.width = msg.Width
 	a.availableHeight = msg.Height - borderStyle.GetVerticalBorderSize()
+	if a.replay != nil {
+		a.availableHeight-- // 回放的状态栏
+	}
 
 	a.categoryPanel.setSize(categoryPanelWidth, a.availableHeight)
 	a.schedulePanel.setSize(schedulePanelWidth, a.availableHeight)
 	a.textLivePanel.SetHeight(a.availableHeight)
 	a.statsPanel.SetSize(statsWidth, a.availableHeight)
+	a.textLiveX = categoryPanelWidth + schedulePanelWidth + statsWidth + 3*borderStyle.GetHorizontalBorderSize()
 }
 
 type focus int
 
-const panelCount = 3
+const panelCount = 4
 
 func (f focus) next() focus {
 	return (f + 1) % panelCount
@@ -150,4 +433,5 @@ const (
 	focusCategory focus = iota
 	focusSchedule
 	focusStats
+	focusTextLive
 )
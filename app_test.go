@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAppFinishesPollsOnResults(t *testing.T) {
+	nba := category{ID: "100000", Name: "NBA"}
+	var m tea.Model = newApp(&fakeProvider{}, &favorites{})
+
+	m, _ = m.Update(categorySelectionMsg(nba))
+	sub := m.(app).poller.subs[scheduleKey(nba)]
+	if sub == nil || !sub.fetching {
+		t.Fatal("category selection does not fetch the schedule")
+	}
+	if !m.(app).schedulePanel.msg.isLoading() {
+		t.Error("schedule panel is not loading")
+	}
+
+	// 加载中的消息不结束请求，不会调度另一条刷新链
+	m, _ = m.Update(newScheduleLoadingMsg(nba))
+	if !sub.fetching || !sub.next.IsZero() {
+		t.Error("loading msg finishes the poll")
+	}
+
+	m, _ = m.Update(newScheduleLoadedMsg(nba, nil))
+	if sub.fetching || sub.next.IsZero() {
+		t.Error("result does not schedule the next poll")
+	}
+
+	m, _ = m.Update(matchSelectionMsg("100000:1"))
+	a := m.(app)
+	if !a.textLivePanel.msg.isLoading() || !a.statsPanel.msg.isLoading() {
+		t.Error("match selection does not set the panels loading")
+	}
+	for _, key := range []subscriptionKey{textLivesKey("100000:1"), statsKey("100000:1")} {
+		if sub := a.poller.subs[key]; sub == nil || !sub.fetching {
+			t.Errorf("%s is not fetching", key)
+		}
+	}
+}
+
+func TestAppExportsFilteredOutMatch(t *testing.T) {
+	prev := cfg.exportDir
+	cfg.exportDir = t.TempDir()
+	t.Cleanup(func() { cfg.exportDir = prev })
+
+	nba := category{ID: "100000", Name: "NBA"}
+	live := match{MID: "100000:1", MatchPeriod: periodInProgress, LeftGoal: "100", RightGoal: "98"}
+	a := newApp(&fakeProvider{}, &favorites{})
+	a.schedulePanel.category = nba
+	a.schedulePanel.onScheduleMsg(newScheduleLoadedMsg(nba, []match{live}))
+	a.textLivePanel.matchID = live.MID
+
+	// 只显示已结束的比赛时，正在导出的比赛不在列表中
+	a.schedulePanel.togglePeriod(periodEnd)
+	if len(a.schedulePanel.list.Items()) != 0 {
+		t.Fatal("match is not filtered out")
+	}
+
+	msg, ok := findMsg[transcriptExportedMsg](a.exportTranscript(exportTranscriptMsg{matchID: live.MID, format: transcriptJSON}))
+	if !ok {
+		t.Fatal("export is not started")
+	}
+	if msg.err != nil {
+		t.Fatal(msg.err)
+	}
+	b, err := os.ReadFile(msg.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"100000:1"`) {
+		t.Errorf("transcript does not contain the match: %s", b)
+	}
+}
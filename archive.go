@@ -0,0 +1,412 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// archive 把文字直播和统计保存到本地，每场比赛一个只追加的NDJSON文件
+//
+// 每行是一条archiveRecord，文字直播每条只保存一次，统计和比赛信息在变化时保存。
+type archive struct {
+	dir string
+
+	mu       sync.Mutex
+	matches  map[string]*archivedMatch // 已读取的比赛，key为mid
+	schedule map[string]match          // 赛程中的比赛，保存时附带比赛信息
+}
+
+type archiveKind string
+
+const (
+	archiveMatch    archiveKind = "match"
+	archiveTextLive archiveKind = "textLive"
+	archiveStats    archiveKind = "stats"
+)
+
+// archiveRecord 文件中的一行
+type archiveRecord struct {
+	Kind      archiveKind `json:"kind"`
+	FetchedAt time.Time   `json:"fetchedAt"`
+	Match     *match      `json:"match,omitempty"`
+	TextLive  *textLive   `json:"textLive,omitempty"`
+	Seq       int         `json:"seq,omitempty"` // 文字直播是比赛的第几条，从0开始
+	Stats     *stats      `json:"stats,omitempty"`
+}
+
+// archivedMatch 一场比赛已保存的内容
+type archivedMatch struct {
+	match     *match
+	textLives map[string]textLive // key为index
+	seqs      map[string]int      // 文字直播的顺序，key为index
+	stats     *stats
+	statsData []byte // 最近一次保存的统计，没有变化时不再保存
+	updatedAt time.Time
+}
+
+func (m *archivedMatch) apply(r archiveRecord) {
+	switch r.Kind {
+	case archiveMatch:
+		m.match = r.Match
+	case archiveTextLive:
+		if r.TextLive != nil {
+			m.textLives[r.TextLive.IndexValue] = *r.TextLive
+			m.seqs[r.TextLive.IndexValue] = r.Seq
+		}
+	case archiveStats:
+		m.stats = r.Stats
+		m.statsData, _ = json.Marshal(r.Stats)
+	}
+	m.updatedAt = r.FetchedAt
+}
+
+// sortedTextLives 按index排序，最新的在前面
+func (m *archivedMatch) sortedTextLives() []textLive {
+	textLives := make([]textLive, 0, len(m.textLives))
+	for _, v := range m.textLives {
+		textLives = append(textLives, v)
+	}
+	slices.SortFunc(textLives, func(a, b textLive) int {
+		return m.seqs[b.IndexValue] - m.seqs[a.IndexValue]
+	})
+	sortTextLives(textLives)
+	return textLives
+}
+
+func newArchive(dir string) *archive {
+	return &archive{
+		dir:      dir,
+		matches:  map[string]*archivedMatch{},
+		schedule: map[string]match{},
+	}
+}
+
+func newArchivedMatch() *archivedMatch {
+	return &archivedMatch{textLives: map[string]textLive{}, seqs: map[string]int{}}
+}
+
+func archivePath(dir, matchID string) string {
+	return filepath.Join(dir, strings.ReplaceAll(matchID, ":", "-")+".ndjson")
+}
+
+// readArchive 读取一场比赛的所有记录，忽略写入中断导致的不完整的最后一行
+func readArchive(path string) ([]archiveRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []archiveRecord
+	lines := bytes.Split(bytes.TrimRight(data, "\n"), []byte("\n"))
+	for i, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var r archiveRecord
+		if err = json.Unmarshal(line, &r); err != nil {
+			if i == len(lines)-1 {
+				break
+			}
+			return nil, fmt.Errorf("archive %s line %d: %w", path, i+1, err)
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// load 返回一场比赛已保存的内容，调用时需要持有锁
+func (a *archive) load(matchID string) (*archivedMatch, error) {
+	if m, ok := a.matches[matchID]; ok {
+		return m, nil
+	}
+
+	m := newArchivedMatch()
+	records, err := readArchive(archivePath(a.dir, matchID))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for _, r := range records {
+		m.apply(r)
+	}
+	a.matches[matchID] = m
+	return m, nil
+}
+
+// append 把records追加到文件中并更新已保存的内容，调用时需要持有锁
+func (a *archive) append(matchID string, m *archivedMatch, records []archiveRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	// 比赛信息有变化时一起保存，方便之后查看比分
+	if v, ok := a.schedule[matchID]; ok && (m.match == nil || *m.match != v) {
+		records = append([]archiveRecord{{Kind: archiveMatch, FetchedAt: records[0].FetchedAt, Match: &v}}, records...)
+	}
+
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(a.dir, 0o750); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(archivePath(a.dir, matchID), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(b.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	for _, r := range records {
+		m.apply(r)
+	}
+	return nil
+}
+
+// rememberSchedule 记录赛程中的比赛信息
+func (a *archive) rememberSchedule(matches []match) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, m := range matches {
+		a.schedule[m.MID] = m
+	}
+}
+
+// saveTextLives 保存还没有保存过的文字直播，ids为比赛所有文字直播的ID，用来确定顺序
+func (a *archive) saveTextLives(matchID string, ids []string, textLives map[string]textLive, now time.Time) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	m, err := a.load(matchID)
+	if err != nil {
+		return err
+	}
+
+	// ID列表只在前面增加，从后往前数的位置不会变化
+	var records []archiveRecord
+	for id, v := range textLives {
+		if _, ok := m.textLives[id]; ok {
+			continue
+		}
+		seq := 0
+		if i := slices.Index(ids, id); i >= 0 {
+			seq = len(ids) - 1 - i
+		}
+		records = append(records, archiveRecord{Kind: archiveTextLive, FetchedAt: now, TextLive: &v, Seq: seq})
+	}
+	slices.SortFunc(records, func(a, b archiveRecord) int {
+		return a.Seq - b.Seq
+	})
+	return a.append(matchID, m, records)
+}
+
+// saveStats 统计有变化时保存
+func (a *archive) saveStats(matchID string, s *stats, now time.Time) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	m, err := a.load(matchID)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(data, m.statsData) {
+		return nil
+	}
+	return a.append(matchID, m, []archiveRecord{{Kind: archiveStats, FetchedAt: now, Stats: s}})
+}
+
+// match 返回一场比赛已保存的内容
+func (a *archive) match(matchID string) (*archivedMatch, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.load(matchID)
+}
+
+// textLives 返回已保存的文字直播，最新的在前面
+func (a *archive) textLives(matchID string) []textLive {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	m, err := a.load(matchID)
+	if err != nil {
+		return nil
+	}
+	return m.sortedTextLives()
+}
+
+func (a *archive) stats(matchID string) *stats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	m, err := a.load(matchID)
+	if err != nil {
+		return nil
+	}
+	return m.stats
+}
+
+// updatedAt 最后一次保存的时间
+func (a *archive) updatedAt(matchID string) time.Time {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	m, err := a.load(matchID)
+	if err != nil {
+		return time.Time{}
+	}
+	return m.updatedAt
+}
+
+// archiveSummary 已保存的一场比赛
+type archiveSummary struct {
+	MID       string    `json:"mid"`
+	Match     *match    `json:"match"`
+	TextLives int       `json:"textLives"`
+	Stats     *stats    `json:"stats"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// listArchives 返回目录中保存的所有比赛，最近更新的在前面
+func listArchives(dir string) ([]archiveSummary, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.ndjson"))
+	if err != nil {
+		return nil, err
+	}
+
+	var summaries []archiveSummary
+	for _, path := range paths {
+		records, err := readArchive(path)
+		if err != nil {
+			return nil, err
+		}
+
+		m := newArchivedMatch()
+		for _, r := range records {
+			m.apply(r)
+		}
+
+		mid := strings.Replace(strings.TrimSuffix(filepath.Base(path), ".ndjson"), "-", ":", 1)
+		if m.match != nil {
+			mid = m.match.MID
+		}
+		summaries = append(summaries, archiveSummary{
+			MID:       mid,
+			Match:     m.match,
+			TextLives: len(m.textLives),
+			Stats:     m.stats,
+			UpdatedAt: m.updatedAt,
+		})
+	}
+
+	slices.SortFunc(summaries, func(a, b archiveSummary) int {
+		return b.UpdatedAt.Compare(a.UpdatedAt)
+	})
+	return summaries, nil
+}
+
+// archiveProvider 保存获取到的文字直播和统计，接口失败时使用保存的内容
+type archiveProvider struct {
+	provider
+	archive *archive
+}
+
+func newArchiveProvider(p provider, a *archive) archiveProvider {
+	return archiveProvider{provider: p, archive: a}
+}
+
+// fallback 接口失败时是否使用保存的内容，取消的请求和子命令不使用
+func fallback(err error) bool {
+	return cfg.offlineFallback && err != nil && !errors.Is(err, context.Canceled)
+}
+
+func (p archiveProvider) fetchSchedule(
+	ctx context.Context,
+	categoryID string,
+	start, end time.Time,
+) ([]match, error) {
+	matches, err := p.provider.fetchSchedule(ctx, categoryID, start, end)
+	if err == nil {
+		p.archive.rememberSchedule(matches)
+	}
+	return matches, err
+}
+
+func (p archiveProvider) fetchMatchHasTextLives(ctx context.Context, matchID string) (bool, error) {
+	hasData, err := p.provider.fetchMatchHasTextLives(ctx, matchID)
+	if fallback(err) && len(p.archive.textLives(matchID)) > 0 {
+		markCached(ctx, p.archive.updatedAt(matchID))
+		return true, nil
+	}
+	return hasData, err
+}
+
+func (p archiveProvider) fetchTextLives(
+	ctx context.Context,
+	matchID string,
+	q textLiveQuery,
+) (textLivePage, error) {
+	page, err := p.provider.fetchTextLives(ctx, matchID, q)
+	if err == nil {
+		// 保存失败不影响获取的结果
+		_ = p.archive.saveTextLives(matchID, page.ids, page.textLives, time.Now())
+		return page, nil
+	}
+	if !fallback(err) {
+		return textLivePage{}, err
+	}
+
+	textLives := p.archive.textLives(matchID)
+	if len(textLives) == 0 {
+		return textLivePage{}, err
+	}
+	ids := make([]string, len(textLives))
+	all := make(map[string]textLive, len(textLives))
+	for i, v := range textLives {
+		ids[i] = v.IndexValue
+		all[v.IndexValue] = v
+	}
+	markCached(ctx, p.archive.updatedAt(matchID))
+	return newTextLivePage(ids, all, q), nil
+}
+
+func (p archiveProvider) fetchStats(ctx context.Context, matchID string) (*stats, error) {
+	s, err := p.provider.fetchStats(ctx, matchID)
+	if err == nil {
+		_ = p.archive.saveStats(matchID, s, time.Now())
+		return s, nil
+	}
+	if !fallback(err) {
+		return nil, err
+	}
+
+	if archived := p.archive.stats(matchID); archived != nil {
+		markCached(ctx, p.archive.updatedAt(matchID))
+		return archived, nil
+	}
+	return nil, err
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func textLiveIndexes(textLives []textLive) []string {
+	indexes := make([]string, len(textLives))
+	for i, v := range textLives {
+		indexes[i] = v.IndexValue
+	}
+	return indexes
+}
+
+func TestArchiveSaveTextLives(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2025, 1, 2, 13, 0, 0, 0, time.UTC)
+	const mid = "100000:1471545"
+
+	a := newArchive(dir)
+	a.rememberSchedule([]match{{MID: mid, LeftName: "湖人", RightName: "勇士"}})
+	ids := []string{"9_1", "10_1", "11_1"}
+	if err := a.saveTextLives(mid, ids, fakeTextLives(ids...), now); err != nil {
+		t.Fatal(err)
+	}
+
+	// 新的文字直播加在ID列表前面，已保存的不再保存
+	ids = append([]string{"8_1"}, ids...)
+	if err := a.saveTextLives(mid, ids, fakeTextLives("8_1", "9_1"), now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := readArchive(archivePath(dir, mid))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var kinds []archiveKind
+	var saved []string
+	var seqs []int
+	for _, r := range records {
+		kinds = append(kinds, r.Kind)
+		if r.Kind == archiveTextLive {
+			saved = append(saved, r.TextLive.IndexValue)
+			seqs = append(seqs, r.Seq)
+		}
+	}
+	// 比赛信息只保存一次，文字直播按从旧到新的顺序追加
+	wantKinds := []archiveKind{archiveMatch, archiveTextLive, archiveTextLive, archiveTextLive, archiveTextLive}
+	if !slices.Equal(kinds, wantKinds) {
+		t.Errorf("kinds = %v, want %v", kinds, wantKinds)
+	}
+	if want := []string{"11_1", "10_1", "9_1", "8_1"}; !slices.Equal(saved, want) {
+		t.Errorf("saved = %v, want %v", saved, want)
+	}
+	if want := []int{0, 1, 2, 3}; !slices.Equal(seqs, want) {
+		t.Errorf("seqs = %v, want %v", seqs, want)
+	}
+
+	// 重新读取文件，最新的在前面
+	m, err := newArchive(dir).match(mid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := textLiveIndexes(m.sortedTextLives()), []string{"8_1", "9_1", "10_1", "11_1"}; !slices.Equal(got, want) {
+		t.Errorf("text lives = %v, want %v", got, want)
+	}
+	if m.match == nil || m.match.LeftName != "湖人" {
+		t.Errorf("match = %+v", m.match)
+	}
+	if !m.updatedAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("updatedAt = %s", m.updatedAt)
+	}
+}
+
+func TestArchiveSaveStats(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	const mid = "100000:1471545"
+
+	a := newArchive(dir)
+	s := testStats()
+	for range 2 {
+		if err := a.saveStats(mid, &s, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.teamStats[0].LeftVal = "41"
+	if err := a.saveStats(mid, &s, now); err != nil {
+		t.Fatal(err)
+	}
+
+	// 没有变化的统计不保存
+	records, err := readArchive(archivePath(dir, mid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+
+	got := newArchive(dir).stats(mid)
+	if got == nil || got.teamStats[0].LeftVal != "41" || got.team.RightName != "勇士" {
+		t.Errorf("stats = %+v", got)
+	}
+}
+
+func TestArchiveProviderFallback(t *testing.T) {
+	const mid = "100000:1471545"
+	p := &fakeProvider{
+		ids:   []string{"9_1", "10_1"},
+		texts: fakeTextLives("9_1", "10_1"),
+	}
+	ap := newArchiveProvider(p, newArchive(t.TempDir()))
+	ctx := context.Background()
+
+	if _, err := ap.fetchTextLives(ctx, mid, textLiveQuery{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 接口失败时使用保存的内容
+	p.err = errors.New("offline")
+	page, err := ap.fetchTextLives(ctx, mid, textLiveQuery{known: map[string]bool{"9_1": true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(page.ids, []string{"9_1", "10_1"}) {
+		t.Errorf("ids = %v", page.ids)
+	}
+	if len(page.textLives) != 1 || page.textLives["10_1"].IndexValue != "10_1" {
+		t.Errorf("text lives = %v, want 10_1", page.textLives)
+	}
+
+	// 没有保存过的比赛返回原来的错误
+	if _, err = ap.fetchTextLives(ctx, "100000:1", textLiveQuery{}); !errors.Is(err, p.err) {
+		t.Errorf("err = %v, want %v", err, p.err)
+	}
+	// 取消的请求不使用保存的内容
+	p.err = context.Canceled
+	if _, err = ap.fetchTextLives(ctx, mid, textLiveQuery{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want canceled", err)
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheMaxAge 启动时删除超过这个时间没有更新的缓存
+const cacheMaxAge = 7 * 24 * time.Hour
+
+// defaultCacheDir 默认的缓存目录，如$XDG_CACHE_HOME/sportx
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "sportx")
+}
+
+// readCache 返回缓存的响应和获取时间，缓存和-record使用相同的文件格式，文件的修改时间就是获取时间
+func readCache(dir string, u string, q url.Values) ([]byte, time.Time, error) {
+	path := fixturePath(dir, u, q)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return body, info.ModTime(), nil
+}
+
+// textLiveCacheMu 同一场比赛的文字直播缓存需要先读取再合并写入
+var textLiveCacheMu sync.Mutex
+
+// textLiveCachePath 文字直播的详情按比赛缓存，每次请求的ids都不同，按URL缓存时每个文件只会用到一次
+func textLiveCachePath(dir, matchID string) string {
+	return filepath.Join(dir, "textLive-"+strings.ReplaceAll(matchID, ":", "-")+".json")
+}
+
+// readTextLiveCache 返回缓存的一场比赛的文字直播和最后一次缓存的时间，key为ID
+func readTextLiveCache(dir, matchID string) (map[string]textLive, time.Time, error) {
+	path := textLiveCachePath(dir, matchID)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	var texts map[string]textLive
+	if err = json.Unmarshal(data, &texts); err != nil {
+		return nil, time.Time{}, err
+	}
+	return texts, info.ModTime(), nil
+}
+
+// cacheTextLives 把获取到的文字直播合并到比赛的缓存中
+func cacheTextLives(matchID string, texts map[string]textLive) error {
+	if cfg.cacheDir == "" || cfg.replayDir != "" || len(texts) == 0 {
+		return nil
+	}
+
+	textLiveCacheMu.Lock()
+	defer textLiveCacheMu.Unlock()
+
+	cached, _, err := readTextLiveCache(cfg.cacheDir, matchID)
+	if err != nil {
+		// 没有缓存或者缓存损坏时重新保存
+		cached = map[string]textLive{}
+	}
+	maps.Copy(cached, texts)
+
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(cfg.cacheDir, 0o750); err != nil {
+		return err
+	}
+	return os.WriteFile(textLiveCachePath(cfg.cacheDir, matchID), data, 0o600)
+}
+
+// cachedTextLives 请求失败时返回缓存的ids中的文字直播，没有缓存时返回err
+func cachedTextLives(ctx context.Context, matchID string, ids []string, err error) (map[string]textLive, error) {
+	if cfg.cacheDir == "" || cfg.replayDir != "" || !cfg.offlineFallback || errors.Is(err, context.Canceled) {
+		return nil, err
+	}
+
+	textLiveCacheMu.Lock()
+	cached, fetchedAt, cacheErr := readTextLiveCache(cfg.cacheDir, matchID)
+	textLiveCacheMu.Unlock()
+	if cacheErr != nil {
+		return nil, err
+	}
+
+	ret := map[string]textLive{}
+	for _, id := range ids {
+		if v, ok := cached[id]; ok {
+			ret[id] = v
+		}
+	}
+	if len(ret) == 0 {
+		return nil, err
+	}
+	markCached(ctx, fetchedAt)
+	return ret, nil
+}
+
+// pruneCache 删除过期的缓存
+func pruneCache(dir string, maxAge time.Duration) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return
+	}
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > maxAge {
+			_ = os.Remove(path)
+		}
+	}
+}
+
+// staleness 数据来自离线缓存时的获取时间
+type staleness struct {
+	offline   bool
+	fetchedAt time.Time
+}
+
+// String 如"离线 3分钟前"，不是离线时为空
+func (s staleness) String() string {
+	if !s.offline {
+		return ""
+	}
+	return "离线 " + ago(time.Since(s.fetchedAt))
+}
+
+func ago(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "刚刚"
+	case d < time.Hour:
+		return fmt.Sprintf("%d分钟前", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%d小时前", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%d天前", int(d.Hours()/24)) //nolint:mnd // 一天24小时
+	}
+}
+
+// fetchInfo 记录一次获取中的请求是否使用了缓存，一次获取可能有多个请求
+type fetchInfo struct {
+	mu sync.Mutex
+	staleness
+}
+
+type fetchInfoKey struct{}
+
+// withFetchInfo 返回的ctx中的请求使用缓存时会记录到fetchInfo
+func withFetchInfo(ctx context.Context) (context.Context, *fetchInfo) {
+	info := &fetchInfo{}
+	return context.WithValue(ctx, fetchInfoKey{}, info), info
+}
+
+// markCached 记录使用了t时获取的缓存，保留最早的时间
+func markCached(ctx context.Context, t time.Time) {
+	info, ok := ctx.Value(fetchInfoKey{}).(*fetchInfo)
+	if !ok {
+		return
+	}
+
+	info.mu.Lock()
+	defer info.mu.Unlock()
+	if !info.offline || t.Before(info.fetchedAt) {
+		info.fetchedAt = t
+	}
+	info.offline = true
+}
+
+func (i *fetchInfo) get() staleness {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.staleness
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeTencentServer 返回indexes中的文字直播，所有的index都有详情
+type fakeTencentServer struct {
+	mu      sync.Mutex
+	indexes []string
+}
+
+func (s *fakeTencentServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/kbs/matchDetail":
+		_, _ = w.Write([]byte(`{"code":0,"data":{"isHasTextLive":true}}`))
+	case "/textLive/index":
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"code": 0,
+			"data": map[string]any{"tabs": []any{map[string]any{"index": s.indexes}}},
+		})
+	case "/textLive/detail":
+		texts := map[string]textLive{}
+		for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+			texts[id] = textLive{IndexValue: id}
+		}
+		_ = json.NewEncoder(w).Encode([]any{0, texts, ""})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *fakeTencentServer) setIndexes(indexes ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.indexes = indexes
+}
+
+func TestTextLivesOfflineColdStart(t *testing.T) {
+	c := cfg
+	c.cacheDir = t.TempDir()
+	c.apiMaxRetries = 0
+	useConfig(t, c)
+
+	const mid = "100000:1471545"
+	fake := &fakeTencentServer{}
+	srv := httptest.NewServer(fake)
+
+	// 在线时分两次获取，每次请求详情的ids不同
+	p := newTencentProvider(newAPIClient(), srv.URL, srv.URL)
+	fetch := textLivesFetcher(p, newTextLiveFeed(p, mid))
+	for _, indexes := range [][]string{{"10_1", "11_1"}, {"9_1", "10_1", "11_1"}} {
+		fake.setIndexes(indexes...)
+		if msg := fetch(context.Background()).(textLivesMsg); msg.err != nil {
+			t.Fatal(msg.err)
+		}
+	}
+	srv.Close()
+
+	// 详情不按URL缓存
+	if paths, _ := filepath.Glob(filepath.Join(cfg.cacheDir, "*textLive_detail*")); len(paths) > 0 {
+		t.Errorf("text live details cached by url: %v", paths)
+	}
+
+	// 离线重新启动，请求所有index的详情，和之前的请求都不同
+	p = newTencentProvider(newAPIClient(), srv.URL, srv.URL)
+	msg := textLivesFetcher(p, newTextLiveFeed(p, mid))(context.Background()).(textLivesMsg)
+	if msg.err != nil {
+		t.Fatalf("offline: %v", msg.err)
+	}
+	var ids []string
+	for _, v := range msg.textLives {
+		ids = append(ids, v.IndexValue)
+	}
+	if want := []string{"9_1", "10_1", "11_1"}; !slices.Equal(ids, want) {
+		t.Errorf("text lives = %v, want %v", ids, want)
+	}
+	if !msg.staleness.offline {
+		t.Error("text lives from the cache are not marked offline")
+	}
+}
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
@@ -11,14 +14,18 @@ import (
 )
 
 type categoryPanel struct {
-	msg categoriesMsg
+	provider  provider
+	favorites *favorites
+	msg       categoriesMsg
 	listPanel
 }
 
-func newCategoryPanel() categoryPanel {
+func newCategoryPanel(p provider, favs *favorites) categoryPanel {
 	return categoryPanel{
+		provider:  p,
+		favorites: favs,
 		msg:       newCategoriesLoadingMsg(),
-		listPanel: newListPanel(categoryDelegate{}),
+		listPanel: newListPanel(categoryDelegate{favorites: favs}),
 	}
 }
 
@@ -26,11 +33,14 @@ func (c categoryPanel) Init() tea.Cmd {
 	return tea.Batch(
 		c.spinner.Tick,
 		func() tea.Msg {
-			categories, err := fetchCategories()
+			ctx, info := withFetchInfo(context.Background())
+			categories, err := c.provider.fetchCategories(ctx)
 			if err != nil {
 				return newCategoriesFailedMsg(err)
 			}
-			return newCategoriesLoadedMsg(categories)
+			msg := newCategoriesLoadedMsg(categories)
+			msg.staleness = info.get()
+			return msg
 		},
 	)
 }
@@ -52,17 +62,23 @@ func (c categoryPanel) Update(msg tea.Msg) (categoryPanel, tea.Cmd) {
 				return c, nil
 			}
 
-			cmd = func() tea.Msg {
-				return categorySelectionMsg(msg.categories[0])
+			categories := c.setCategories()
+			// 默认选中我的关注之后的第一个分类
+			selection := categories[1]
+			c.list.Select(1)
+			if i, ok := findDefaultCategory(categories); ok {
+				selection = categories[i]
+				c.list.Select(i)
 			}
-
-			var items []list.Item
-			for _, category := range msg.categories {
-				items = append(items, category)
+			cmd = func() tea.Msg {
+				return categorySelectionMsg(selection)
 			}
-			c.list.SetItems(items)
 		}
 		return c, cmd
+	case tea.KeyMsg:
+		if key.Matches(msg, cfg.keys.star) {
+			return c, c.toggleStar()
+		}
 	}
 
 	before, ok1 := c.list.SelectedItem().(category)
@@ -77,11 +93,58 @@ func (c categoryPanel) Update(msg tea.Msg) (categoryPanel, tea.Cmd) {
 	return c, cmd
 }
 
+// setCategories 按关注的分类重新排列列表，返回排列后的分类
+func (c *categoryPanel) setCategories() []category {
+	categories := c.favorites.arrange(c.msg.categories)
+	items := make([]list.Item, len(categories))
+	for i, v := range categories {
+		items[i] = v
+	}
+	c.list.SetItems(items)
+	return categories
+}
+
+// toggleStar 关注或者取消关注选中的分类，选中的分类不变
+func (c *categoryPanel) toggleStar() tea.Cmd {
+	selection, ok := c.list.SelectedItem().(category)
+	if !ok || selection.equal(favoriteCategory) {
+		return nil
+	}
+
+	starred, err := c.favorites.toggleCategory(selection)
+	for i, v := range c.setCategories() {
+		if v.equal(selection) {
+			c.list.Select(i)
+			break
+		}
+	}
+	return favoritesChanged(selection.Name, starred, err)
+}
+
 func (c categoryPanel) View(focused bool) string {
-	return c.render(focused, c.msg.status, c.msg.err)
+	offline := ""
+	if c.msg.staleness.offline {
+		offline = "离线" // 分类面板太窄，只显示是否离线
+	}
+	return c.render(focused, c.msg.status, c.msg.err, offline)
 }
 
-type categoryDelegate struct{}
+// findDefaultCategory 启动时选中的分类，没有指定分类时使用指定比赛所属的赛事
+func findDefaultCategory(categories []category) (int, bool) {
+	if cfg.defaultCategory != "" {
+		return findCategory(categories, cfg.defaultCategory)
+	}
+
+	competitionID, _, ok := strings.Cut(cfg.defaultMatch, ":")
+	if !ok {
+		return 0, false
+	}
+	return findCategory(categories, competitionID)
+}
+
+type categoryDelegate struct {
+	favorites *favorites
+}
 
 func (d categoryDelegate) Height() int {
 	return 1
@@ -101,9 +164,14 @@ func (d categoryDelegate) Render(w io.Writer, m list.Model, index int, item list
 		return
 	}
 
-	content := fmt.Sprintf("  %s", i.Name)
+	name := i.Name
+	if d.favorites.isCategory(i) {
+		name = "★" + name
+	}
+
+	content := fmt.Sprintf("  %s", name)
 	if index == m.Index() {
-		content = fmt.Sprintf("> %s", i.Name)
+		content = fmt.Sprintf("> %s", name)
 		content = listFocusedStyle.Render(content)
 	}
 
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// command 不启动界面的子命令
+type command struct {
+	name  string
+	args  string
+	usage string
+	run   func(ctx context.Context, fs *flag.FlagSet, args []string) error
+}
+
+var commands = []command{
+	{name: "categories", usage: "输出所有分类", run: runCategories},
+	{name: "schedule", usage: "输出分类的赛程，分类通过--category指定", run: runSchedule},
+	{name: "follow", args: "<比赛ID>", usage: "持续输出比赛的文字直播，比赛结束后退出", run: runFollow},
+	{name: "stats", args: "<比赛ID>", usage: "输出比赛的比分、球队和球员统计", run: runStats},
+	{name: "archive", args: "[比赛ID]", usage: "列出保存的比赛，指定比赛时输出文字直播和统计", run: runArchive},
+	{name: "replay", args: "<文件>", usage: "回放archive保存的比赛，支持倍速、暂停、快进快退和单步", run: runReplay},
+}
+
+func findCommand(name string) (command, bool) {
+	i := slices.IndexFunc(commands, func(c command) bool {
+		return c.name == name
+	})
+	if i < 0 {
+		return command{}, false
+	}
+	return commands[i], true
+}
+
+// usageError 参数错误，退出码为2
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+func newUsageError(format string, a ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, a...)}
+}
+
+// runCommand 执行子命令并返回退出码：0成功，1请求失败，2参数错误
+func runCommand(c command, args []string) int {
+	fs := flag.NewFlagSet("sportx "+c.name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "用法: sportx %s [参数] %s\n\n%s\n\n", c.name, c.args, c.usage)
+		fs.PrintDefaults()
+	}
+
+	// 获取失败时返回非0的退出码，不使用离线缓存和保存的内容
+	cfg.offlineFallback = false
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err := c.run(ctx, fs, args)
+	if err == nil {
+		return 0
+	}
+
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+
+	fmt.Fprintf(os.Stderr, "sportx %s: %v\n", c.name, err)
+	var usageErr *usageError
+	if errors.As(err, &usageErr) {
+		return 2 //nolint:mnd // 参数错误
+	}
+	return 1
+}
+
+// parseCommandFlags 解析子命令的参数并加载配置，返回剩余的参数
+func parseCommandFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	flags := newConfigFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, err
+		}
+		return nil, &usageError{msg: err.Error()}
+	}
+	if err := flags.load(); err != nil {
+		return nil, &usageError{msg: err.Error()}
+	}
+	return fs.Args(), nil
+}
+
+// outputFormat 子命令的输出格式
+type outputFormat string
+
+const (
+	formatTable  outputFormat = "table"
+	formatJSON   outputFormat = "json"
+	formatNDJSON outputFormat = "ndjson"
+	formatCSV    outputFormat = "csv"
+)
+
+func parseOutputFormat(v string, supported ...outputFormat) (outputFormat, error) {
+	f := outputFormat(v)
+	if slices.Contains(supported, f) {
+		return f, nil
+	}
+
+	names := make([]string, len(supported))
+	for i, s := range supported {
+		names[i] = string(s)
+	}
+	return "", newUsageError("invalid format %q, expected one of: %s", v, strings.Join(names, ", "))
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeNDJSON 每行一个JSON
+func writeNDJSON[T any](w io.Writer, items []T) error {
+	enc := json.NewEncoder(w)
+	for _, v := range items {
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTable 按显示宽度对齐输出表格，中文占两个宽度
+func writeTable(w io.Writer, header []string, rows [][]string) error {
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			if i < len(widths) {
+				widths[i] = max(widths[i], ansi.StringWidth(cell))
+			}
+		}
+	}
+
+	for _, row := range append([][]string{header}, rows...) {
+		var b strings.Builder
+		for i, cell := range row {
+			if i == len(row)-1 {
+				b.WriteString(cell)
+				break
+			}
+			b.WriteString(cell)
+			b.WriteString(strings.Repeat(" ", widths[i]-ansi.StringWidth(cell)+2)) //nolint:mnd // 列间距
+		}
+		if _, err := fmt.Fprintln(w, strings.TrimRight(b.String(), " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+func runArchive(_ context.Context, fs *flag.FlagSet, args []string) error {
+	format := fs.String("format", "", "输出格式，列表为table或json，比赛为markdown、html或json")
+	rest, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if cfg.archiveDir == "" {
+		return newUsageError("archive directory is not set, use --archive, SPORTX_ARCHIVE or archive_dir")
+	}
+
+	switch len(rest) {
+	case 0:
+		return listArchivedMatches(*format)
+	case 1:
+		return showArchivedMatch(rest[0], *format)
+	default:
+		return newUsageError("expected at most one match id")
+	}
+}
+
+func listArchivedMatches(format string) error {
+	if format == "" {
+		format = string(formatTable)
+	}
+	f, err := parseOutputFormat(format, formatTable, formatJSON)
+	if err != nil {
+		return err
+	}
+
+	summaries, err := listArchives(cfg.archiveDir)
+	if err != nil {
+		return err
+	}
+	if summaries == nil {
+		summaries = []archiveSummary{}
+	}
+
+	if f == formatJSON {
+		return writeJSON(os.Stdout, summaries)
+	}
+
+	rows := make([][]string, len(summaries))
+	for i, s := range summaries {
+		desc, teams, status := "", "", ""
+		if s.Match != nil {
+			desc = s.Match.MatchDesc
+			teams = s.Match.LeftName
+			if s.Match.RightName != "" {
+				teams = fmt.Sprintf("%s %s %s", s.Match.LeftName, matchScore(*s.Match), s.Match.RightName)
+			}
+			status = s.Match.periodText()
+		}
+		rows[i] = []string{
+			s.MID, desc, teams, status,
+			fmt.Sprint(s.TextLives),
+			s.UpdatedAt.Local().Format(time.DateTime),
+		}
+	}
+	return writeTable(os.Stdout, []string{"ID", "比赛", "对阵", "状态", "文字直播", "更新时间"}, rows)
+}
+
+// showArchivedMatch 把保存的比赛按导出文字直播的格式输出
+func showArchivedMatch(matchID, format string) error {
+	if format == "" {
+		format = string(transcriptMarkdown)
+	}
+	f := transcriptFormat(format)
+	if f != transcriptMarkdown && f != transcriptHTML && f != transcriptJSON {
+		return newUsageError("invalid format %q, expected one of: markdown, html, json", format)
+	}
+
+	m, err := newArchive(cfg.archiveDir).match(matchID)
+	if err != nil {
+		return err
+	}
+	if len(m.textLives) == 0 && m.stats == nil {
+		return fmt.Errorf("match %s is not archived in %s", matchID, cfg.archiveDir)
+	}
+
+	t := transcript{
+		match:      match{MID: matchID},
+		stats:      m.stats,
+		textLives:  m.sortedTextLives(),
+		exportedAt: time.Now(),
+	}
+	if m.match != nil {
+		t.match = *m.match
+	}
+
+	data, err := t.render(f)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+)
+
+func runCategories(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	format := fs.String("format", string(formatTable), "输出格式：table、json或ndjson")
+	if _, err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+
+	f, err := parseOutputFormat(*format, formatTable, formatJSON, formatNDJSON)
+	if err != nil {
+		return err
+	}
+
+	categories, err := newProvider().fetchCategories(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch f {
+	case formatJSON:
+		return writeJSON(os.Stdout, categories)
+	case formatNDJSON:
+		return writeNDJSON(os.Stdout, categories)
+	default:
+		rows := make([][]string, len(categories))
+		for i, c := range categories {
+			rows[i] = []string{c.ID, c.Name}
+		}
+		return writeTable(os.Stdout, []string{"ID", "名称"}, rows)
+	}
+}
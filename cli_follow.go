@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+)
+
+func runFollow(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	asJSON := fs.Bool("json", false, "每行输出一条JSON")
+	rest, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return newUsageError("expected exactly one match id, e.g. 100000:1471545")
+	}
+	matchID := rest[0]
+
+	p := newProvider()
+	f := &follower{
+		feed:    newTextLiveFeed(p, matchID),
+		printed: map[string]bool{},
+		w:       os.Stdout,
+		json:    *asJSON,
+	}
+
+	hasData, err := f.wait(ctx, p)
+	if err == nil && !hasData {
+		return fmt.Errorf("match %s ended without text live", matchID)
+	}
+	if err == nil {
+		err = f.run(ctx, p)
+	}
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// follower 持续输出一场比赛新的文字直播，每条只输出一次
+type follower struct {
+	feed    *textLiveFeed
+	printed map[string]bool // 已输出的index
+	w       io.Writer
+	json    bool
+}
+
+// wait 比赛还没有文字直播时每隔textLiveRefreshInterval检查一次，直到有文字直播或者比赛结束
+func (f *follower) wait(ctx context.Context, p provider) (bool, error) {
+	matchID := f.feed.matchID
+	for checked := false; ; checked = true {
+		hasData, err := p.fetchMatchHasTextLives(ctx, matchID)
+		switch {
+		case err == nil && hasData:
+			return true, nil
+		case err != nil && !checked:
+			// 第一次检查失败时退出，比如比赛ID错误
+			return false, err
+		case err != nil:
+			f.warn(err)
+		case !checked:
+			fmt.Fprintf(os.Stderr, "sportx follow: match %s has no text live yet, waiting\n", matchID)
+		}
+
+		s, err := p.fetchStats(ctx, matchID)
+		if err == nil && s.livePeriod == periodEnd {
+			return false, nil
+		}
+		if err != nil {
+			f.warn(err)
+		}
+
+		if err = sleep(ctx, cfg.textLiveRefreshInterval); err != nil {
+			return false, err
+		}
+	}
+}
+
+func (f *follower) run(ctx context.Context, p provider) error {
+	if err := f.update(ctx); err != nil {
+		return err
+	}
+
+	for {
+		s, err := p.fetchStats(ctx, f.feed.matchID)
+		if err == nil && s.livePeriod == periodEnd {
+			// 比赛结束后再获取一次，避免漏掉最后的内容
+			return f.update(ctx)
+		}
+		if err != nil {
+			f.warn(err)
+		}
+
+		if err = sleep(ctx, cfg.textLiveRefreshInterval); err != nil {
+			return err
+		}
+
+		// 第一次获取成功后，临时的失败不退出
+		if err = f.update(ctx); err != nil {
+			f.warn(err)
+		}
+	}
+}
+
+func (f *follower) update(ctx context.Context) error {
+	textLives, err := f.feed.update(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 最新的在前面，按时间顺序输出
+	for _, v := range slices.Backward(textLives) {
+		if f.printed[v.IndexValue] {
+			continue
+		}
+		f.printed[v.IndexValue] = true
+		if err = f.print(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *follower) print(v textLive) error {
+	if f.json {
+		return writeNDJSON(f.w, []textLive{v})
+	}
+
+	line := v.text()
+	if line == "" {
+		return nil
+	}
+	_, err := fmt.Fprintln(f.w, line)
+	return err
+}
+
+func (f *follower) warn(err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "sportx follow: %v\n", err)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+func runReplay(_ context.Context, fs *flag.FlagSet, args []string) error {
+	speed := fs.Int("speed", 1, "回放倍速：1、5或30")
+	rest, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return newUsageError("expected exactly one archive file, e.g. 100000-1471545.ndjson")
+	}
+	if !slices.Contains(replaySpeeds, *speed) {
+		return newUsageError("invalid speed %d, expected one of: 1, 5, 30", *speed)
+	}
+
+	records, err := readArchive(rest[0])
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("archive %s is empty", rest[0])
+	}
+
+	matchID := archiveMatchID(rest[0], records)
+	clock := newReplayClock(records)
+	clock.changeSpeed(slices.Index(replaySpeeds, *speed))
+
+	// 回放时的数据来自本地文件，刷新间隔使用最小值
+	cfg.scheduleRefreshInterval = time.Second
+	cfg.statsRefreshInterval = time.Second
+	cfg.textLiveRefreshInterval = time.Second
+	cfg.defaultCategory = replayCategory.ID
+	cfg.defaultMatch = matchID
+
+	p := newReplayProvider(matchID, records, clock)
+	return runTUI(newReplayApp(p, clock))
+}
+
+// archiveMatchID 优先使用记录中的比赛ID，没有时从文件名获取
+func archiveMatchID(path string, records []archiveRecord) string {
+	for _, r := range records {
+		if r.Kind == archiveMatch && r.Match != nil {
+			return r.Match.MID
+		}
+	}
+	return strings.Replace(strings.TrimSuffix(filepath.Base(path), ".ndjson"), "-", ":", 1)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"os"
+	"slices"
+	"time"
+)
+
+// matchStatuses 子命令中比赛状态的名称
+var matchStatuses = map[string]period{
+	"upcoming": periodComing,
+	"live":     periodInProgress,
+	"ended":    periodEnd,
+}
+
+func statusName(p period) string {
+	for k, v := range matchStatuses {
+		if v == p {
+			return k
+		}
+	}
+	return "unknown"
+}
+
+func runSchedule(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	from := fs.String("from", "", "开始日期，默认为今天")
+	to := fs.String("to", "", "结束日期，默认为开始日期之后5天")
+	status := fs.String("status", "", "只输出指定状态的比赛：live、upcoming或ended")
+	format := fs.String("format", string(formatTable), "输出格式：table、json或csv")
+	if _, err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+
+	f, err := parseOutputFormat(*format, formatTable, formatJSON, formatCSV)
+	if err != nil {
+		return err
+	}
+
+	if *from == "" {
+		*from = currentTime().Format(time.DateOnly)
+	}
+	start, end, err := parseDateRange(*from, *to)
+	if err != nil {
+		return err
+	}
+
+	var filter period
+	if *status != "" {
+		var ok bool
+		if filter, ok = matchStatuses[*status]; !ok {
+			return newUsageError("invalid status %q, expected one of: live, upcoming, ended", *status)
+		}
+	}
+
+	if cfg.defaultCategory == "" {
+		return newUsageError("category is required, use --category or SPORTX_CATEGORY")
+	}
+
+	p := newProvider()
+	c, err := resolveCategory(ctx, p, cfg.defaultCategory)
+	if err != nil {
+		return err
+	}
+
+	matches, err := p.fetchSchedule(ctx, c.ID, start, end)
+	if err != nil {
+		return err
+	}
+	if filter != "" {
+		matches = slices.DeleteFunc(matches, func(m match) bool {
+			return m.MatchPeriod != filter
+		})
+	}
+	if matches == nil {
+		matches = []match{}
+	}
+
+	switch f {
+	case formatJSON:
+		return writeJSON(os.Stdout, matches)
+	case formatCSV:
+		return writeScheduleCSV(matches)
+	default:
+		rows := make([][]string, len(matches))
+		for i, m := range matches {
+			rows[i] = []string{m.MID, m.StartTime, m.MatchDesc, m.LeftName, matchScore(m), m.RightName, m.periodText()}
+		}
+		return writeTable(os.Stdout, []string{"ID", "开始时间", "比赛", "主队", "比分", "客队", "状态"}, rows)
+	}
+}
+
+func parseDateRange(from, to string) (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation(time.DateOnly, from, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, newUsageError("invalid --from %q, expected YYYY-MM-DD", from)
+	}
+
+	end := start.AddDate(0, 0, scheduleDays)
+	if to != "" {
+		end, err = time.ParseInLocation(time.DateOnly, to, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, newUsageError("invalid --to %q, expected YYYY-MM-DD", to)
+		}
+	}
+
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, newUsageError("--to %s is before --from %s", to, from)
+	}
+	return start, end, nil
+}
+
+// resolveCategory 按ID或者名称查找分类
+func resolveCategory(ctx context.Context, p provider, v string) (category, error) {
+	categories, err := p.fetchCategories(ctx)
+	if err != nil {
+		return category{}, err
+	}
+
+	i, ok := findCategory(categories, v)
+	if !ok {
+		return category{}, newUsageError("unknown category %q, run `sportx categories` to list them", v)
+	}
+	return categories[i], nil
+}
+
+func matchScore(m match) string {
+	if m.RightName == "" {
+		return ""
+	}
+	return m.LeftGoal + "-" + m.RightGoal
+}
+
+func writeScheduleCSV(matches []match) error {
+	w := csv.NewWriter(os.Stdout)
+	_ = w.Write([]string{
+		"mid", "startTime", "matchDesc", "leftName", "leftGoal", "rightName", "rightGoal", "status",
+	})
+	for _, m := range matches {
+		_ = w.Write([]string{
+			m.MID, m.StartTime, m.MatchDesc, m.LeftName, m.LeftGoal, m.RightName, m.RightGoal,
+			statusName(m.MatchPeriod),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
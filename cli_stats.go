@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func runStats(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	format := fs.String("format", string(formatTable), "输出格式：table、json或csv")
+	rest, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return newUsageError("expected exactly one match id, e.g. 100000:1471545")
+	}
+
+	f, err := parseOutputFormat(*format, formatTable, formatJSON, formatCSV)
+	if err != nil {
+		return err
+	}
+
+	s, err := newProvider().fetchStats(ctx, rest[0])
+	if err != nil {
+		return err
+	}
+
+	switch f {
+	case formatJSON:
+		return writeJSON(os.Stdout, s)
+	case formatCSV:
+		return writeStatsCSV(s.tables())
+	default:
+		for i, t := range s.tables() {
+			if i > 0 {
+				fmt.Fprintln(os.Stdout)
+			}
+			fmt.Fprintln(os.Stdout, t.name)
+			if err = writeTable(os.Stdout, t.head, t.rows); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// writeStatsCSV 每个表格一段，第一行是表格名称，段之间用空行分隔
+func writeStatsCSV(tables []statsTable) error {
+	w := csv.NewWriter(os.Stdout)
+	for i, t := range tables {
+		if i > 0 {
+			w.Flush()
+			fmt.Fprintln(os.Stdout)
+		}
+		_ = w.Write([]string{t.name})
+		_ = w.Write(t.head)
+		_ = w.WriteAll(t.rows)
+	}
+	w.Flush()
+	return w.Error()
+}
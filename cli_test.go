@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteTable(t *testing.T) {
+	var b bytes.Buffer
+	err := writeTable(&b, []string{"ID", "名称"}, [][]string{
+		{"208", "NBA"},
+		{"100000", "英超"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 中文占两个宽度，最后一列不补空格
+	want := "ID      名称\n208     NBA\n100000  英超\n"
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	var b bytes.Buffer
+	err := writeNDJSON(&b, []category{{ID: "208", Name: "NBA"}, {ID: "100000", Name: "英超"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "{\"columnId\":\"208\",\"name\":\"NBA\"}\n{\"columnId\":\"100000\",\"name\":\"英超\"}\n"
+	if b.String() != want {
+		t.Errorf("got %q, want %q", b.String(), want)
+	}
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	f, err := parseOutputFormat("json", formatTable, formatJSON)
+	if err != nil || f != formatJSON {
+		t.Errorf("got %q, %v", f, err)
+	}
+
+	_, err = parseOutputFormat("csv", formatTable, formatJSON)
+	var usageErr *usageError
+	if !errors.As(err, &usageErr) {
+		t.Errorf("err = %v, want usage error", err)
+	}
+}
+
+func TestParseDateRange(t *testing.T) {
+	day := func(s string) time.Time {
+		d, _ := time.ParseInLocation(time.DateOnly, s, time.Local)
+		return d
+	}
+
+	tests := []struct {
+		name      string
+		from, to  string
+		wantStart time.Time
+		wantEnd   time.Time
+		wantErr   bool
+	}{
+		{"default end", "2025-01-02", "", day("2025-01-02"), day("2025-01-07"), false},
+		{"explicit end", "2025-01-02", "2025-01-03", day("2025-01-02"), day("2025-01-03"), false},
+		{"same day", "2025-01-02", "2025-01-02", day("2025-01-02"), day("2025-01-02"), false},
+		{"invalid from", "01/02", "", time.Time{}, time.Time{}, true},
+		{"invalid to", "2025-01-02", "tomorrow", time.Time{}, time.Time{}, true},
+		{"end before start", "2025-01-02", "2025-01-01", time.Time{}, time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := parseDateRange(tt.from, tt.to)
+			if tt.wantErr {
+				var usageErr *usageError
+				if !errors.As(err, &usageErr) {
+					t.Errorf("err = %v, want usage error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("got %s - %s, want %s - %s", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestMatchScoreAndStatus(t *testing.T) {
+	tests := []struct {
+		m          match
+		wantScore  string
+		wantStatus string
+	}{
+		{match{LeftName: "湖人", RightName: "勇士", LeftGoal: "100", RightGoal: "98", MatchPeriod: periodEnd}, "100-98", "ended"},
+		{match{LeftName: "湖人", RightName: "勇士", LeftGoal: "0", RightGoal: "0", MatchPeriod: periodComing}, "0-0", "upcoming"},
+		// 没有对手的比赛不显示比分
+		{match{LeftName: "F1 澳大利亚站", MatchPeriod: periodInProgress}, "", "live"},
+	}
+	for _, tt := range tests {
+		if got := matchScore(tt.m); got != tt.wantScore {
+			t.Errorf("matchScore(%s) = %q, want %q", tt.m.LeftName, got, tt.wantScore)
+		}
+		if got := statusName(tt.m.MatchPeriod); got != tt.wantStatus {
+			t.Errorf("statusName(%s) = %q, want %q", tt.m.MatchPeriod, got, tt.wantStatus)
+		}
+	}
+}
+
+func testStats() stats {
+	return stats{
+		livePeriod: periodEnd,
+		team:       &team{LeftName: "湖人", RightName: "勇士"},
+		goal:       &goalStats{Head: []string{"1", "2"}, Rows: [][]string{{"20", "30"}, {"25", "28"}}},
+		teamStats:  []teamStats{{Text: "篮板", LeftVal: "40", RightVal: "38"}},
+		playerStats: [][]playerStats{
+			{{Head: []string{"球员", "得分"}}, {Row: []string{"詹姆斯", "30"}}},
+			{{Head: []string{"球员", "得分"}}, {Row: []string{"库里", "35"}}},
+		},
+	}
+}
+
+func TestStatsTables(t *testing.T) {
+	want := []statsTable{
+		{name: "比分", head: []string{"", "1", "2"}, rows: [][]string{{"湖人", "20", "30"}, {"勇士", "25", "28"}}},
+		{name: "球队统计", head: []string{"", "湖人", "勇士"}, rows: [][]string{{"篮板", "40", "38"}}},
+		{name: "湖人球员统计", head: []string{"球员", "得分"}, rows: [][]string{{"詹姆斯", "30"}}},
+		{name: "勇士球员统计", head: []string{"球员", "得分"}, rows: [][]string{{"库里", "35"}}},
+	}
+
+	got := testStats().tables()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestStatsMarshalJSON(t *testing.T) {
+	b, err := json.Marshal(testStats())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"livePeriod":"2","team":{"leftName":"湖人","rightName":"勇士"},` +
+		`"goal":{"head":["1","2"],"rows":[["20","30"],["25","28"]]},` +
+		`"teamStats":[{"leftVal":"40","rightVal":"38","text":"篮板"}],` +
+		`"playerStats":[{"team":"湖人","head":["球员","得分"],"rows":[["詹姆斯","30"]]},` +
+		`{"team":"勇士","head":["球员","得分"],"rows":[["库里","35"]]}]}`
+	if string(b) != want {
+		t.Errorf("got %s\nwant %s", b, want)
+	}
+}
+
+// startingProvider 检查checks次之后才有文字直播
+type startingProvider struct {
+	*fakeProvider
+	checks int
+}
+
+func (p *startingProvider) fetchMatchHasTextLives(ctx context.Context, matchID string) (bool, error) {
+	p.checks--
+	if _, err := p.fakeProvider.fetchMatchHasTextLives(ctx, matchID); err != nil {
+		return false, err
+	}
+	return p.checks <= 0, nil
+}
+
+func TestFollowerWait(t *testing.T) {
+	prev := cfg.textLiveRefreshInterval
+	cfg.textLiveRefreshInterval = time.Millisecond
+	t.Cleanup(func() { cfg.textLiveRefreshInterval = prev })
+
+	tests := []struct {
+		name         string
+		checks       int
+		period       period
+		want         bool
+		wantRequests int // 检查是否有文字直播的次数
+	}{
+		{"has text live", 1, periodComing, true, 1},
+		{"text live starts", 3, periodComing, true, 3},
+		{"match ended", 3, periodEnd, false, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &startingProvider{
+				fakeProvider: &fakeProvider{stats: &stats{livePeriod: tt.period}},
+				checks:       tt.checks,
+			}
+			f := &follower{feed: newTextLiveFeed(p, "100000:1"), printed: map[string]bool{}, w: io.Discard}
+
+			got, err := f.wait(context.Background(), p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("wait = %v, want %v", got, tt.want)
+			}
+			var n int
+			for _, r := range p.takeRequests() {
+				if strings.HasPrefix(r, "has text lives") {
+					n++
+				}
+			}
+			if n != tt.wantRequests {
+				t.Errorf("checked %d times, want %d", n, tt.wantRequests)
+			}
+		})
+	}
+}
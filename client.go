@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// apiClient 所有接口请求共用的HTTP客户端
+//
+// 复用连接，限制全局请求频率，并且合并同时发起的相同请求。
+type apiClient struct {
+	http    *http.Client
+	limiter *rateLimiter
+	group   singleflight.Group
+}
+
+//nolint:mnd // 连接池参数
+func newAPIClient() *apiClient {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          16,
+		MaxIdleConnsPerHost:   8,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: cfg.apiRequestTimeout,
+	}
+
+	if cfg.cacheDir != "" {
+		go pruneCache(cfg.cacheDir, cacheMaxAge)
+	}
+
+	return &apiClient{
+		http:    &http.Client{Transport: transport},
+		limiter: newRateLimiter(cfg.apiRateLimit, cfg.apiRateBurst),
+	}
+}
+
+// requestCached 请求成功时保存响应，失败时使用之前保存的响应
+func (c *apiClient) requestCached(ctx context.Context, u string, q url.Values) ([]byte, error) {
+	body, err := c.requestShared(ctx, u, q)
+	if cfg.cacheDir == "" {
+		return body, err
+	}
+	if err == nil {
+		// 保存失败不影响请求的结果
+		_ = recordFixture(cfg.cacheDir, u, q, body)
+		return body, nil
+	}
+	if !cfg.offlineFallback || errors.Is(err, context.Canceled) {
+		return nil, err
+	}
+
+	cached, fetchedAt, cacheErr := readCache(cfg.cacheDir, u, q)
+	if cacheErr != nil {
+		return nil, err
+	}
+	markCached(ctx, fetchedAt)
+	return cached, nil
+}
+
+func (c *apiClient) request(ctx context.Context, u string, p map[string]string, ret any) error {
+	return c.decode(ctx, u, p, ret, c.requestCached)
+}
+
+// requestUncached 不按URL缓存响应，用于每次参数都不同的请求，由调用方自己缓存
+func (c *apiClient) requestUncached(ctx context.Context, u string, p map[string]string, ret any) error {
+	return c.decode(ctx, u, p, ret, c.requestShared)
+}
+
+// decode 通过get请求并解析响应，回放时从录制的响应读取
+func (c *apiClient) decode(
+	ctx context.Context,
+	u string,
+	p map[string]string,
+	ret any,
+	get func(ctx context.Context, u string, q url.Values) ([]byte, error),
+) error {
+	q := url.Values{}
+	for k, v := range p {
+		q.Add(k, v)
+	}
+
+	var body []byte
+	var err error
+	if cfg.replayDir != "" {
+		body, err = replayFixture(cfg.replayDir, u, q)
+	} else {
+		body, err = get(ctx, u, q)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = json.Unmarshal(body, &ret); err != nil {
+		return &decodeError{url: u, err: err}
+	}
+	return nil
+}
+
+// requestShared 相同的请求共享一次网络往返，body只读，各自解析
+func (c *apiClient) requestShared(ctx context.Context, u string, q url.Values) ([]byte, error) {
+	for {
+		ch := c.group.DoChan(fixtureKey(u, q), func() (any, error) {
+			return c.requestWithRetry(ctx, u, q)
+		})
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case r := <-ch:
+			// 发起请求的一方被取消，自己的请求仍然有效时重新请求
+			if errors.Is(r.Err, context.Canceled) && ctx.Err() == nil {
+				continue
+			}
+			if r.Err != nil {
+				return nil, r.Err
+			}
+			body, _ := r.Val.([]byte)
+			return body, nil
+		}
+	}
+}
+
+// requestWithRetry 对超时、5xx和429进行有限次数的重试，每次重试的间隔指数增长并加入随机抖动
+func (c *apiClient) requestWithRetry(ctx context.Context, u string, q url.Values) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		body, err := c.requestOnce(ctx, u, q)
+		if err == nil {
+			if cfg.recordDir != "" {
+				if err = recordFixture(cfg.recordDir, u, q, body); err != nil {
+					return nil, err
+				}
+			}
+			return body, nil
+		}
+
+		if attempt >= cfg.apiMaxRetries || !retryable(err) {
+			return nil, err
+		}
+		if err = sleep(ctx, retryBackoff(attempt)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func retryBackoff(attempt int) time.Duration {
+	d := cfg.apiRetryBaseDelay << attempt
+	if d <= 0 || d > cfg.apiRetryMaxDelay {
+		d = cfg.apiRetryMaxDelay
+	}
+	// 在[d/2, d)之间随机，避免多个请求同时重试
+	return d/2 + rand.N(d/2+1) //nolint:gosec // 抖动不需要安全随机数
+}
+
+func (c *apiClient) requestOnce(ctx context.Context, u string, q url.Values) ([]byte, error) {
+	if err := c.limiter.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.apiRequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add(
+		"User-Agent",
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/111.0.0.0 Safari/537.36",
+	)
+	req.URL.RawQuery = q.Encode()
+
+	hresp, err := c.http.Do(req)
+	if err != nil {
+		return nil, &networkError{url: u, err: err}
+	}
+
+	defer hresp.Body.Close()
+
+	if hresp.StatusCode != http.StatusOK {
+		return nil, &statusError{url: u, code: hresp.StatusCode}
+	}
+
+	body, err := io.ReadAll(hresp.Body)
+	if err != nil {
+		return nil, &networkError{url: u, err: err}
+	}
+	return body, nil
+}
+
+// rateLimiter 令牌桶限流，每秒rate个请求，最多累积burst个
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    int
+	next     time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if rate <= 0 {
+		return &rateLimiter{}
+	}
+	return &rateLimiter{
+		interval: time.Duration(float64(time.Second) / rate),
+		burst:    max(burst, 1),
+	}
+}
+
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l.interval == 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	// 空闲时最多累积burst个令牌
+	earliest := now.Add(-time.Duration(l.burst-1) * l.interval)
+	if l.next.Before(earliest) {
+		l.next = earliest
+	}
+	d := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	return sleep(ctx, d)
+}
+
+// sleep 等待d时间，ctx被取消时提前返回
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRequestCoalescing(t *testing.T) {
+	conf := cfg
+	conf.cacheDir = ""
+	useConfig(t, conf)
+
+	var hits atomic.Int32
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		_, _ = w.Write([]byte(`{"code":0}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := newAPIClient()
+	const n = 5
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var resp struct {
+				Code int `json:"code"`
+			}
+			errs[i] = c.request(context.Background(), srv.URL+"/matchUnion/list", map[string]string{"columnId": "100000"}, &resp)
+		}()
+	}
+
+	// 第一个请求到达之后再等其它请求加入
+	<-started
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("server hits = %d, want 1", got)
+	}
+}
+
+func TestRequestRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  func(w http.ResponseWriter, r *http.Request)
+		wantHits int32
+		wantText string
+	}{
+		{
+			name: "response header timeout",
+			handler: func(_ http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done()
+			},
+			wantHits: 2,
+			wantText: "请求超时，稍后重试",
+		},
+		{
+			// 连接断开等不是超时的网络错误不重试
+			name: "connection closed",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				conn, _, _ := w.(http.Hijacker).Hijack()
+				conn.Close()
+			},
+			wantHits: 1,
+			wantText: "网络连接失败，稍后重试",
+		},
+		{
+			name: "server error",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			},
+			wantHits: 2,
+			wantText: "服务异常(HTTP 502)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cfg
+			c.apiRequestTimeout = 50 * time.Millisecond
+			c.apiMaxRetries = 1
+			c.apiRetryBaseDelay, c.apiRetryMaxDelay = time.Millisecond, time.Millisecond
+			c.cacheDir = ""
+			useConfig(t, c)
+
+			var hits atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				hits.Add(1)
+				tt.handler(w, r)
+			}))
+			t.Cleanup(srv.Close)
+
+			var resp struct{}
+			err := newAPIClient().request(context.Background(), srv.URL+"/matchUnion/list", nil, &resp)
+			if err == nil {
+				t.Fatal("want error")
+			}
+			if got := hits.Load(); got != tt.wantHits {
+				t.Errorf("server hits = %d, want %d", got, tt.wantHits)
+			}
+			if got := errorText(err); got != tt.wantText {
+				t.Errorf("errorText = %q, want %q", got, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    float64
+		burst   int
+		waits   int
+		atLeast time.Duration
+		atMost  time.Duration
+	}{
+		{"unlimited", 0, 0, 100, 0, 10 * time.Millisecond},
+		{"within burst", 100, 5, 5, 0, 10 * time.Millisecond},
+		// 前3个立即返回，之后每10ms一个
+		{"over burst", 100, 3, 6, 25 * time.Millisecond, 200 * time.Millisecond},
+		{"burst at least one", 100, 0, 3, 15 * time.Millisecond, 200 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newRateLimiter(tt.rate, tt.burst)
+
+			start := time.Now()
+			for range tt.waits {
+				if err := l.wait(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if d := time.Since(start); d < tt.atLeast || d > tt.atMost {
+				t.Errorf("%d waits took %s, want between %s and %s", tt.waits, d, tt.atLeast, tt.atMost)
+			}
+		})
+	}
+}
+
+func TestRateLimiterWaitCanceled(t *testing.T) {
+	l := newRateLimiter(1, 1)
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 下一个令牌在1秒之后，取消时立即返回
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := l.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want deadline exceeded", err)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Errorf("canceled wait took %s", d)
+	}
+}
@@ -1,6 +1,18 @@
 package main
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
 
 //nolint:mnd // 配置文件
 var cfg = config{
@@ -9,6 +21,19 @@ var cfg = config{
 	statsRefreshInterval:    10 * time.Second,
 	textLiveRefreshInterval: 5 * time.Second,
 	apiRequestTimeout:       10 * time.Second,
+	apiMaxRetries:           3,
+	apiRetryBaseDelay:       500 * time.Millisecond,
+	apiRetryMaxDelay:        5 * time.Second,
+	apiRateLimit:            5,
+	apiRateBurst:            10,
+	matchWebBaseURL:         "https://matchweb.sports.qq.com",
+	appBaseURL:              "https://app.sports.qq.com",
+	cacheDir:                defaultCacheDir(),
+	offlineFallback:         true,
+	mouse:                   true,
+	notifyBell:              true,
+	focusedColor:            "#EE6FF8",
+	keys:                    defaultKeyMap(),
 }
 
 type config struct {
@@ -17,4 +42,248 @@ type config struct {
 	statsRefreshInterval    time.Duration // 统计刷新间隔
 	textLiveRefreshInterval time.Duration // 文本直播刷新间隔
 	apiRequestTimeout       time.Duration // API请求超时时间
+	apiMaxRetries           int           // API请求失败后的最大重试次数
+	apiRetryBaseDelay       time.Duration // API请求第一次重试的间隔
+	apiRetryMaxDelay        time.Duration // API请求重试的最大间隔
+	apiRateLimit            float64       // 每秒最多发起的API请求数，0表示不限制
+	apiRateBurst            int           // 允许瞬间发起的API请求数
+	matchWebBaseURL         string        // matchweb.sports.qq.com 接口地址
+	appBaseURL              string        // app.sports.qq.com 接口地址
+	recordDir               string        // 保存接口响应的目录
+	replayDir               string        // 回放接口响应的目录，不再请求网络
+	defaultCategory         string        // 启动时选中的分类，ID或者名称
+	defaultMatch            string        // 启动时选中的比赛
+	mouse                   bool          // 是否使用鼠标
+	inline                  bool          // 不使用全屏模式
+	exportDir               string        // 导出文字直播的目录，为空时为当前目录
+	archiveDir              string        // 保存文字直播和统计的目录，为空时不保存
+	cacheDir                string        // 缓存接口响应的目录，接口失败时使用，为空时不缓存
+	offlineFallback         bool          // 接口失败时使用缓存，子命令中获取失败需要返回错误，不使用缓存
+	notifyBell              bool          // 比分或者比赛状态变化时响铃
+	notifyCommand           []string      // 比分或者比赛状态变化时运行的命令，通知的内容作为最后一个参数
+	focusedColor            string        // 选中时的颜色
+	borderColor             string        // 边框颜色，为空时根据终端背景选择
+	keys                    keyMap        // 快捷键
+}
+
+// fileConfig 配置文件的格式，没有配置的项使用默认值
+type fileConfig struct {
+	TextLiveCount   int    `toml:"text_live_count"`
+	DefaultCategory string `toml:"default_category"`
+	Mouse           bool   `toml:"mouse"`
+	Inline          bool   `toml:"inline"`
+	ExportDir       string `toml:"export_dir"`
+	ArchiveDir      string `toml:"archive_dir"`
+	CacheDir        string `toml:"cache_dir"`
+	Refresh         struct {
+		Schedule duration `toml:"schedule"`
+		Stats    duration `toml:"stats"`
+		TextLive duration `toml:"text_live"`
+	} `toml:"refresh"`
+	API struct {
+		MatchWebURL    string   `toml:"matchweb_url"`
+		AppURL         string   `toml:"app_url"`
+		Timeout        duration `toml:"timeout"`
+		MaxRetries     int      `toml:"max_retries"`
+		RetryBaseDelay duration `toml:"retry_base_delay"`
+		RetryMaxDelay  duration `toml:"retry_max_delay"`
+		RateLimit      float64  `toml:"rate_limit"`
+		RateBurst      int      `toml:"rate_burst"`
+	} `toml:"api"`
+	Theme struct {
+		FocusedColor string `toml:"focused_color"`
+		BorderColor  string `toml:"border_color"`
+	} `toml:"theme"`
+	Notify struct {
+		Bell    bool     `toml:"bell"`
+		Command []string `toml:"command"`
+	} `toml:"notify"`
+	Keybindings map[string][]string `toml:"keybindings"`
+}
+
+// duration 配置文件中的时间，如"10s"、"1m"
+type duration struct {
+	time.Duration
+}
+
+func (d *duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("invalid duration %q, expected a value like \"10s\"", text)
+	}
+	d.Duration = v
+	return nil
+}
+
+// defaultConfigPath 默认的配置文件路径，如$XDG_CONFIG_HOME/sportx/config.toml
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "sportx", "config.toml")
+}
+
+// load 读取配置文件，required为false时文件不存在不报错
+func (c *config) load(path string, required bool) error {
+	if path == "" {
+		return nil
+	}
+
+	var f fileConfig
+	f.fromConfig(*c)
+	meta, err := toml.DecodeFile(path, &f)
+	if err != nil {
+		if !required && errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			return fmt.Errorf("config %s: %s", path, parseErr.ErrorWithPosition())
+		}
+		return fmt.Errorf("config %s: %w", path, err)
+	}
+
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for i, k := range undecoded {
+			keys[i] = k.String()
+		}
+		return fmt.Errorf("config %s: unknown keys: %s", path, strings.Join(keys, ", "))
+	}
+
+	if err = f.toConfig(c); err != nil {
+		return fmt.Errorf("config %s: %w", path, err)
+	}
+	return nil
+}
+
+func (f *fileConfig) fromConfig(c config) {
+	f.TextLiveCount = c.textLiveCount
+	f.DefaultCategory = c.defaultCategory
+	f.Mouse = c.mouse
+	f.Inline = c.inline
+	f.ExportDir = c.exportDir
+	f.ArchiveDir = c.archiveDir
+	f.CacheDir = c.cacheDir
+	f.Refresh.Schedule.Duration = c.scheduleRefreshInterval
+	f.Refresh.Stats.Duration = c.statsRefreshInterval
+	f.Refresh.TextLive.Duration = c.textLiveRefreshInterval
+	f.API.MatchWebURL = c.matchWebBaseURL
+	f.API.AppURL = c.appBaseURL
+	f.API.Timeout.Duration = c.apiRequestTimeout
+	f.API.MaxRetries = c.apiMaxRetries
+	f.API.RetryBaseDelay.Duration = c.apiRetryBaseDelay
+	f.API.RetryMaxDelay.Duration = c.apiRetryMaxDelay
+	f.API.RateLimit = c.apiRateLimit
+	f.API.RateBurst = c.apiRateBurst
+	f.Theme.FocusedColor = c.focusedColor
+	f.Theme.BorderColor = c.borderColor
+	f.Notify.Bell = c.notifyBell
+	f.Notify.Command = c.notifyCommand
+}
+
+func (f fileConfig) toConfig(c *config) error {
+	c.textLiveCount = f.TextLiveCount
+	c.defaultCategory = f.DefaultCategory
+	c.mouse = f.Mouse
+	c.inline = f.Inline
+	c.exportDir = f.ExportDir
+	c.archiveDir = f.ArchiveDir
+	c.cacheDir = f.CacheDir
+	c.scheduleRefreshInterval = f.Refresh.Schedule.Duration
+	c.statsRefreshInterval = f.Refresh.Stats.Duration
+	c.textLiveRefreshInterval = f.Refresh.TextLive.Duration
+	c.matchWebBaseURL = f.API.MatchWebURL
+	c.appBaseURL = f.API.AppURL
+	c.apiRequestTimeout = f.API.Timeout.Duration
+	c.apiMaxRetries = f.API.MaxRetries
+	c.apiRetryBaseDelay = f.API.RetryBaseDelay.Duration
+	c.apiRetryMaxDelay = f.API.RetryMaxDelay.Duration
+	c.apiRateLimit = f.API.RateLimit
+	c.apiRateBurst = f.API.RateBurst
+	c.focusedColor = f.Theme.FocusedColor
+	c.borderColor = f.Theme.BorderColor
+	c.notifyBell = f.Notify.Bell
+	c.notifyCommand = f.Notify.Command
+	return c.keys.bind(f.Keybindings)
+}
+
+func (c config) validate() error {
+	if c.recordDir != "" && c.replayDir != "" {
+		return errors.New("record and replay can not be used together")
+	}
+	if c.textLiveCount <= 0 {
+		return fmt.Errorf("text_live_count must be greater than 0, got %d", c.textLiveCount)
+	}
+
+	for _, v := range []struct {
+		name string
+		d    time.Duration
+		min  time.Duration
+	}{
+		{"refresh.schedule", c.scheduleRefreshInterval, time.Second},
+		{"refresh.stats", c.statsRefreshInterval, time.Second},
+		{"refresh.text_live", c.textLiveRefreshInterval, time.Second},
+		{"api.timeout", c.apiRequestTimeout, time.Millisecond},
+		{"api.retry_base_delay", c.apiRetryBaseDelay, time.Millisecond},
+		{"api.retry_max_delay", c.apiRetryMaxDelay, c.apiRetryBaseDelay},
+	} {
+		if v.d < v.min {
+			return fmt.Errorf("%s must be at least %s, got %s", v.name, v.min, v.d)
+		}
+	}
+
+	if c.apiMaxRetries < 0 {
+		return fmt.Errorf("api.max_retries must not be negative, got %d", c.apiMaxRetries)
+	}
+	if c.apiRateLimit < 0 {
+		return fmt.Errorf("api.rate_limit must not be negative, got %v", c.apiRateLimit)
+	}
+	if c.apiRateBurst <= 0 {
+		return fmt.Errorf("api.rate_burst must be greater than 0, got %d", c.apiRateBurst)
+	}
+
+	if err := validateBaseURL("api.matchweb_url", c.matchWebBaseURL); err != nil {
+		return err
+	}
+	if err := validateBaseURL("api.app_url", c.appBaseURL); err != nil {
+		return err
+	}
+
+	if len(c.notifyCommand) > 0 && c.notifyCommand[0] == "" {
+		return errors.New("notify.command must start with a program name")
+	}
+
+	if err := validateColor("theme.focused_color", c.focusedColor, false); err != nil {
+		return err
+	}
+	return validateColor("theme.border_color", c.borderColor, true)
+}
+
+func validateBaseURL(name, v string) error {
+	u, err := url.Parse(v)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %w", name, v, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid %s %q: scheme and host are required", name, v)
+	}
+	return nil
+}
+
+var hexColorRegexp = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// validateColor 颜色可以是"#RRGGBB"、"#RGB"或者0-255的ANSI颜色
+func validateColor(name, v string, allowEmpty bool) error {
+	if v == "" && allowEmpty {
+		return nil
+	}
+	if hexColorRegexp.MatchString(v) {
+		return nil
+	}
+	if n, err := strconv.Atoi(v); err == nil && n >= 0 && n <= 255 {
+		return nil
+	}
+	return fmt.Errorf("invalid %s %q, expected \"#RRGGBB\" or an ANSI color 0-255", name, v)
 }
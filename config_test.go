@@ -0,0 +1,219 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useConfig 测试结束后恢复全局的配置
+func useConfig(t *testing.T, c config) {
+	t.Helper()
+
+	prev := cfg
+	cfg = c
+	t.Cleanup(func() { cfg = prev })
+}
+
+func TestConfigFlagsLoad(t *testing.T) {
+	defaults := cfg
+
+	tests := []struct {
+		name    string
+		file    string
+		env     map[string]string
+		args    []string
+		check   func(c config) bool
+		wantErr string
+	}{
+		{
+			name:  "defaults",
+			check: func(c config) bool { return c.textLiveCount == 40 && c.scheduleRefreshInterval == 10*time.Second },
+		},
+		{
+			name:  "file over defaults",
+			file:  "text_live_count = 10\n[refresh]\nschedule = \"20s\"\n",
+			check: func(c config) bool { return c.textLiveCount == 10 && c.scheduleRefreshInterval == 20*time.Second },
+		},
+		{
+			name: "env over file",
+			file: "text_live_count = 10\ndefault_category = \"CBA\"\n",
+			env:  map[string]string{"SPORTX_TEXT_LIVE_COUNT": "20"},
+			check: func(c config) bool {
+				return c.textLiveCount == 20 && c.defaultCategory == "CBA"
+			},
+		},
+		{
+			name: "flag over env",
+			file: "text_live_count = 10\n",
+			env:  map[string]string{"SPORTX_TEXT_LIVE_COUNT": "20", "SPORTX_CATEGORY": "NBA"},
+			args: []string{"--text-live-count", "30"},
+			check: func(c config) bool {
+				return c.textLiveCount == 30 && c.defaultCategory == "NBA"
+			},
+		},
+		{
+			// --refresh同时修改三个间隔，之后的参数覆盖之前的
+			name: "flags in order",
+			file: "[refresh]\nstats = \"20s\"\n",
+			args: []string{"--refresh", "30s", "--no-mouse"},
+			check: func(c config) bool {
+				return c.statsRefreshInterval == 30*time.Second && c.textLiveRefreshInterval == 30*time.Second && !c.mouse
+			},
+		},
+		{
+			name:    "unknown key",
+			file:    "text_live_cnt = 10\n",
+			wantErr: "unknown keys: text_live_cnt",
+		},
+		{
+			name:    "invalid env",
+			env:     map[string]string{"SPORTX_TIMEOUT": "soon"},
+			wantErr: "invalid SPORTX_TIMEOUT",
+		},
+		{
+			name:    "invalid after merge",
+			file:    "text_live_count = 10\n",
+			args:    []string{"--text-live-count", "0"},
+			wantErr: "text_live_count must be greater than 0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfig(t, defaults)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			path := filepath.Join(t.TempDir(), "config.toml")
+			if err := os.WriteFile(path, []byte(tt.file), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			fs := flag.NewFlagSet("sportx", flag.ContinueOnError)
+			f := newConfigFlags(fs)
+			if err := fs.Parse(append([]string{"--config", path}, tt.args...)); err != nil {
+				t.Fatal(err)
+			}
+
+			err := f.load()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.check(cfg) {
+				t.Errorf("unexpected config %+v", cfg)
+			}
+		})
+	}
+}
+
+func TestConfigLoad(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		required bool
+		check    func(c config) bool
+		wantErr  string
+	}{
+		{
+			name:  "file over defaults",
+			file:  "text_live_count = 10\n[refresh]\nschedule = \"20s\"\n",
+			check: func(c config) bool { return c.textLiveCount == 10 && c.scheduleRefreshInterval == 20*time.Second },
+		},
+		{
+			name: "keep unset defaults",
+			file: "[theme]\nfocused_color = \"#FFFFFF\"\n",
+			check: func(c config) bool {
+				return c.focusedColor == "#FFFFFF" && c.textLiveCount == 40 && c.statsRefreshInterval == 10*time.Second
+			},
+		},
+		{
+			name:    "unknown key",
+			file:    "text_live_cnt = 10\n",
+			wantErr: "unknown keys: text_live_cnt",
+		},
+		{
+			name:    "invalid duration",
+			file:    "[refresh]\nstats = \"soon\"\n",
+			wantErr: "invalid duration",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.toml")
+			if err := os.WriteFile(path, []byte(tt.file), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			c := cfg
+			err := c.load(path, tt.required)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.check(c) {
+				t.Errorf("unexpected config %+v", c)
+			}
+		})
+	}
+}
+
+func TestConfigLoadMissing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+
+	c := cfg
+	if err := c.load(path, false); err != nil {
+		t.Errorf("optional config: %v", err)
+	}
+	if err := c.load(path, true); err == nil {
+		t.Error("required config: want error")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *config)
+		wantErr string
+	}{
+		{"defaults", func(*config) {}, ""},
+		{"record and replay", func(c *config) { c.recordDir, c.replayDir = "a", "b" }, "record and replay"},
+		{"text live count", func(c *config) { c.textLiveCount = 0 }, "text_live_count"},
+		{"refresh too short", func(c *config) { c.statsRefreshInterval = time.Millisecond }, "refresh.stats"},
+		{"retry max below base", func(c *config) { c.apiRetryMaxDelay = c.apiRetryBaseDelay / 2 }, "api.retry_max_delay"},
+		{"negative retries", func(c *config) { c.apiMaxRetries = -1 }, "api.max_retries"},
+		{"url without host", func(c *config) { c.appBaseURL = "localhost:8080" }, "api.app_url"},
+		{"empty notify command", func(c *config) { c.notifyCommand = []string{"", "x"} }, "notify.command"},
+		{"invalid color", func(c *config) { c.focusedColor = "pink" }, "theme.focused_color"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cfg
+			tt.modify(&c)
+
+			err := c.validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// networkError 网络请求失败，包括超时
+type networkError struct {
+	url string
+	err error
+}
+
+func (e *networkError) Error() string {
+	return fmt.Sprintf("request %s: %v", e.url, e.err)
+}
+
+func (e *networkError) Unwrap() error {
+	return e.err
+}
+
+// timeout 请求的超时和连接、读取响应的超时，如ResponseHeaderTimeout
+func (e *networkError) timeout() bool {
+	if errors.Is(e.err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(e.err, &netErr) && netErr.Timeout()
+}
+
+// statusError 接口返回的HTTP状态码不是200
+type statusError struct {
+	url  string
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("request %s failed: %d", e.url, e.code)
+}
+
+// apiError 接口返回的code不为0
+type apiError struct {
+	api  string
+	code int
+	msg  string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("fetch %s failed, code: %d, msg: %s", e.api, e.code, e.msg)
+}
+
+// decodeError 接口响应解析失败
+type decodeError struct {
+	url string
+	err error
+}
+
+func (e *decodeError) Error() string {
+	return fmt.Sprintf("decode %s: %v", e.url, e.err)
+}
+
+func (e *decodeError) Unwrap() error {
+	return e.err
+}
+
+// retryable 超时、5xx和429可以重试，连接失败等其它网络错误重试也不会成功
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var netErr *networkError
+	if errors.As(err, &netErr) {
+		return netErr.timeout()
+	}
+
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code == http.StatusTooManyRequests ||
+			statusErr.code >= http.StatusInternalServerError
+	}
+
+	return false
+}
+
+// errorText 面板中展示的错误信息
+func errorText(err error) string {
+	var netErr *networkError
+	var statusErr *statusError
+	var apiErr *apiError
+	var decodeErr *decodeError
+
+	switch {
+	case errors.As(err, &netErr):
+		if netErr.timeout() {
+			return "请求超时，稍后重试"
+		}
+		return "网络连接失败，稍后重试"
+	case errors.As(err, &statusErr):
+		if statusErr.code == http.StatusTooManyRequests {
+			return "请求过于频繁，稍后重试"
+		}
+		return fmt.Sprintf("服务异常(HTTP %d)", statusErr.code)
+	case errors.As(err, &apiErr):
+		if apiErr.msg != "" {
+			return fmt.Sprintf("接口错误: %s(%d)", apiErr.msg, apiErr.code)
+		}
+		return fmt.Sprintf("接口错误(%d)", apiErr.code)
+	case errors.As(err, &decodeErr):
+		return "数据解析失败"
+	default:
+		return "加载失败: " + err.Error()
+	}
+}
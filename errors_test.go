@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// errNetTimeout 和ResponseHeaderTimeout一样只实现了net.Error的超时
+var errNetTimeout = &url.Error{Op: "Get", URL: "u", Err: &net.DNSError{IsTimeout: true}}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"timeout", &networkError{url: "u", err: context.DeadlineExceeded}, true},
+		{"net timeout", &networkError{url: "u", err: errNetTimeout}, true},
+		{"connection refused", &networkError{url: "u", err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}}, false},
+		{"no such host", &networkError{url: "u", err: &net.DNSError{IsNotFound: true}}, false},
+		{"canceled", &networkError{url: "u", err: context.Canceled}, false},
+		{"500", &statusError{url: "u", code: http.StatusInternalServerError}, true},
+		{"503 wrapped", fmt.Errorf("fetch: %w", &statusError{url: "u", code: http.StatusServiceUnavailable}), true},
+		{"429", &statusError{url: "u", code: http.StatusTooManyRequests}, true},
+		{"404", &statusError{url: "u", code: http.StatusNotFound}, false},
+		{"api error", &apiError{api: "schedule", code: 1}, false},
+		{"decode error", &decodeError{url: "u", err: errors.New("bad json")}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryable(tt.err); got != tt.want {
+				t.Errorf("retryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	prevBase, prevMax := cfg.apiRetryBaseDelay, cfg.apiRetryMaxDelay
+	cfg.apiRetryBaseDelay, cfg.apiRetryMaxDelay = 100*time.Millisecond, time.Second
+	t.Cleanup(func() { cfg.apiRetryBaseDelay, cfg.apiRetryMaxDelay = prevBase, prevMax })
+
+	tests := []struct {
+		attempt int
+		max     time.Duration // 抖动之前的间隔，结果在[max/2, max]之间
+	}{
+		{0, 100 * time.Millisecond},
+		{1, 200 * time.Millisecond},
+		{3, 800 * time.Millisecond},
+		{4, time.Second},
+		{100, time.Second}, // 溢出时使用最大间隔
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.attempt), func(t *testing.T) {
+			for range 20 {
+				if d := retryBackoff(tt.attempt); d < tt.max/2 || d > tt.max {
+					t.Fatalf("retryBackoff(%d) = %s, want between %s and %s", tt.attempt, d, tt.max/2, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestErrorText(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"timeout", &networkError{url: "u", err: context.DeadlineExceeded}, "请求超时，稍后重试"},
+		{"net timeout", &networkError{url: "u", err: errNetTimeout}, "请求超时，稍后重试"},
+		{"network", &networkError{url: "u", err: errors.New("no such host")}, "网络连接失败，稍后重试"},
+		{"429", &statusError{url: "u", code: http.StatusTooManyRequests}, "请求过于频繁，稍后重试"},
+		{"502", &statusError{url: "u", code: http.StatusBadGateway}, "服务异常(HTTP 502)"},
+		{"api error with msg", &apiError{api: "stats", code: 3, msg: "参数错误"}, "接口错误: 参数错误(3)"},
+		{"api error", &apiError{api: "stats", code: 3}, "接口错误(3)"},
+		{"decode", fmt.Errorf("stats: %w", &decodeError{url: "u", err: errors.New("eof")}), "数据解析失败"},
+		{"other", errors.New("boom"), "加载失败: boom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorText(tt.err); got != tt.want {
+				t.Errorf("errorText = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/sync/errgroup"
+)
+
+// favoriteCategory 虚拟分类，赛程为关注的分类和球队的比赛
+var favoriteCategory = category{
+	ID:   "favorites",
+	Name: "我的关注",
+}
+
+// favorites 关注的球队和分类，修改后立即保存到文件
+type favorites struct {
+	path string // 为空时不保存
+
+	mu   sync.Mutex
+	data favoritesData
+}
+
+type favoritesData struct {
+	Teams      []favoriteTeam `json:"teams"`
+	Categories []category     `json:"categories"`
+}
+
+// favoriteTeam 关注的球队，同名的球队按赛事区分
+type favoriteTeam struct {
+	Name          string `json:"name"`
+	CompetitionID string `json:"competitionId"` // 比赛ID的前半部分，也是赛事分类的columnId
+}
+
+// defaultFavoritesPath 默认的文件路径，和配置文件在同一个目录
+func defaultFavoritesPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "sportx", "favorites.json")
+}
+
+// loadFavorites 读取关注的球队和分类，文件不存在时为空
+func loadFavorites(path string) (*favorites, error) {
+	f := &favorites{path: path}
+	if path == "" {
+		return f, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("favorites %s: %w", path, err)
+	}
+	if err = json.Unmarshal(data, &f.data); err != nil {
+		return nil, fmt.Errorf("favorites %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// save 调用时需要持有锁
+func (f *favorites) save() error {
+	if f.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(f.data, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(f.path), 0o750); err != nil {
+		return err
+	}
+	return os.WriteFile(f.path, data, 0o600)
+}
+
+// competitionID 比赛所属的赛事，如"100000:1471545"为"100000"
+func competitionID(matchID string) string {
+	id, _, _ := strings.Cut(matchID, ":")
+	return id
+}
+
+func (f *favorites) isTeam(m match, name string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return slices.Contains(f.data.Teams, favoriteTeam{Name: name, CompetitionID: competitionID(m.MID)})
+}
+
+// toggleTeam 关注或者取消关注比赛中的球队，返回是否关注
+func (f *favorites) toggleTeam(m match, name string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	team := favoriteTeam{Name: name, CompetitionID: competitionID(m.MID)}
+	starred := !slices.Contains(f.data.Teams, team)
+	if starred {
+		f.data.Teams = append(f.data.Teams, team)
+	} else {
+		f.data.Teams = slices.DeleteFunc(f.data.Teams, func(t favoriteTeam) bool {
+			return t == team
+		})
+	}
+	return starred, f.save()
+}
+
+func (f *favorites) isCategory(c category) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return slices.ContainsFunc(f.data.Categories, c.equal)
+}
+
+// toggleCategory 关注或者取消关注分类，返回是否关注
+func (f *favorites) toggleCategory(c category) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	starred := !slices.ContainsFunc(f.data.Categories, c.equal)
+	if starred {
+		f.data.Categories = append(f.data.Categories, c)
+	} else {
+		f.data.Categories = slices.DeleteFunc(f.data.Categories, c.equal)
+	}
+	return starred, f.save()
+}
+
+// arrange 我的关注在最前面，然后是关注的分类，其它分类保持原来的顺序
+func (f *favorites) arrange(categories []category) []category {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ret := []category{favoriteCategory}
+	var others []category
+	for _, c := range categories {
+		if slices.ContainsFunc(f.data.Categories, c.equal) {
+			ret = append(ret, c)
+		} else {
+			others = append(others, c)
+		}
+	}
+	return append(ret, others...)
+}
+
+// columnIDs 我的关注需要获取赛程的分类：关注的分类和关注的球队所属的赛事
+func (f *favorites) columnIDs() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var ids []string
+	for _, c := range f.data.Categories {
+		ids = append(ids, c.ID)
+	}
+	for _, t := range f.data.Teams {
+		ids = append(ids, t.CompetitionID)
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+// favoritesChanged 关注或者取消关注后的提示
+func favoritesChanged(name string, starred bool, err error) tea.Cmd {
+	text := "已取消关注 " + name
+	if starred {
+		text = "已关注 " + name
+	}
+	return func() tea.Msg {
+		return favoritesChangedMsg{text: text, err: err}
+	}
+}
+
+// fetchSchedule 合并所有相关分类的赛程，关注的分类保留全部比赛，其它分类只保留关注的球队的比赛，按开始时间排序
+func (f *favorites) fetchSchedule(ctx context.Context, p provider, start, end time.Time) ([]match, error) {
+	ids := f.columnIDs()
+	schedules := make([][]match, len(ids))
+
+	g, ctx := errgroup.WithContext(ctx)
+	for i, id := range ids {
+		g.Go(func() error {
+			matches, err := p.fetchSchedule(ctx, id, start, end)
+			schedules[i] = matches
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var matches []match
+	for i, schedule := range schedules {
+		starred := f.isCategory(category{ID: ids[i]})
+		for _, m := range schedule {
+			if seen[m.MID] || !(starred || f.isTeam(m, m.LeftName) || f.isTeam(m, m.RightName)) {
+				continue
+			}
+			seen[m.MID] = true
+			matches = append(matches, m)
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b match) int {
+		return strings.Compare(a.StartTime, b.StartTime)
+	})
+	return matches, nil
+}
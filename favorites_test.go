@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFavoritesFetchSchedule(t *testing.T) {
+	schedules := map[string][]match{
+		"100000": {
+			{MID: "100000:1", StartTime: "2025-01-02 10:00:00", LeftName: "湖人", RightName: "勇士"},
+			{MID: "100000:2", StartTime: "2025-01-02 09:00:00", LeftName: "凯尔特人", RightName: "尼克斯"},
+		},
+		"100008": {
+			{MID: "100008:1", StartTime: "2025-01-02 08:00:00", LeftName: "广东", RightName: "湖人"},
+		},
+	}
+	nba := category{ID: "100000", Name: "NBA"}
+	cba := category{ID: "100008", Name: "CBA"}
+	lakers := favoriteTeam{Name: "湖人", CompetitionID: "100000"}
+	knicks := favoriteTeam{Name: "尼克斯", CompetitionID: "100000"}
+
+	tests := []struct {
+		name         string
+		data         favoritesData
+		err          error
+		wantRequests []string
+		want         []string
+		wantErr      bool
+	}{
+		{
+			name: "nothing starred",
+		},
+		{
+			name:         "starred teams",
+			data:         favoritesData{Teams: []favoriteTeam{lakers, knicks}},
+			wantRequests: []string{"schedule 100000"},
+			want:         []string{"100000:2", "100000:1"},
+		},
+		{
+			// 同名的球队按赛事区分
+			name:         "team in other competition",
+			data:         favoritesData{Teams: []favoriteTeam{lakers, {Name: "辽宁", CompetitionID: "100008"}}},
+			wantRequests: []string{"schedule 100000", "schedule 100008"},
+			want:         []string{"100000:1"},
+		},
+		{
+			name:         "starred category keeps all matches",
+			data:         favoritesData{Categories: []category{cba}, Teams: []favoriteTeam{knicks}},
+			wantRequests: []string{"schedule 100000", "schedule 100008"},
+			want:         []string{"100008:1", "100000:2"},
+		},
+		{
+			name:         "category and team in it",
+			data:         favoritesData{Categories: []category{nba}, Teams: []favoriteTeam{lakers}},
+			wantRequests: []string{"schedule 100000"},
+			want:         []string{"100000:2", "100000:1"},
+		},
+		{
+			name:         "request failed",
+			data:         favoritesData{Categories: []category{nba}},
+			err:          errors.New("boom"),
+			wantRequests: []string{"schedule 100000"},
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &fakeProvider{schedules: schedules, err: tt.err}
+			f := &favorites{data: tt.data}
+
+			matches, err := f.fetchSchedule(context.Background(), p, time.Now(), time.Now())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+
+			if got := p.takeRequests(); !slices.Equal(got, tt.wantRequests) {
+				t.Errorf("requests = %q, want %q", got, tt.wantRequests)
+			}
+			var got []string
+			for _, m := range matches {
+				got = append(got, m.MID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
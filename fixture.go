@@ -0,0 +1,84 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// fixtureClockFile 保存录制开始的时间，回放时请求的日期和录制时相同
+const fixtureClockFile = "clock"
+
+// currentTime 计算赛程日期使用的当前时间，回放时从录制开始的时间计时
+var currentTime = time.Now
+
+// fixtureKey 以URL和排序后的参数作为响应的唯一标识
+func fixtureKey(u string, q url.Values) string {
+	if len(q) == 0 {
+		return u
+	}
+	return u + "?" + q.Encode()
+}
+
+// fixturePath 返回响应文件路径，文件名为接口路径加上key的哈希
+func fixturePath(dir string, u string, q url.Values) string {
+	name := u
+	if parsed, err := url.Parse(u); err == nil {
+		name = parsed.Host + parsed.Path
+	}
+	name = strings.NewReplacer("/", "_", ":", "_", ".", "_").Replace(name)
+
+	sum := sha256.Sum256([]byte(fixtureKey(u, q)))
+	return filepath.Join(dir, fmt.Sprintf("%s-%s.json", name, hex.EncodeToString(sum[:6])))
+}
+
+func recordFixture(dir string, u string, q url.Values, body []byte) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	return os.WriteFile(fixturePath(dir, u, q), body, 0o600)
+}
+
+func replayFixture(dir string, u string, q url.Values) ([]byte, error) {
+	body, err := os.ReadFile(fixturePath(dir, u, q))
+	if err != nil {
+		return nil, fmt.Errorf("replay %s: %w", fixtureKey(u, q), err)
+	}
+	return body, nil
+}
+
+// setupFixtureClock 录制时保存开始的时间，回放时把currentTime调整到录制的时间，没有保存时间的目录使用当前时间
+func setupFixtureClock(c config) error {
+	switch {
+	case c.recordDir != "":
+		if err := os.MkdirAll(c.recordDir, 0o750); err != nil {
+			return err
+		}
+		path := filepath.Join(c.recordDir, fixtureClockFile)
+		return os.WriteFile(path, []byte(time.Now().Format(time.RFC3339)), 0o600)
+	case c.replayDir != "":
+		b, err := os.ReadFile(filepath.Join(c.replayDir, fixtureClockFile))
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		recordedAt, err := time.Parse(time.RFC3339, strings.TrimSpace(string(b)))
+		if err != nil {
+			return fmt.Errorf("invalid replay clock: %w", err)
+		}
+		offset := time.Until(recordedAt)
+		currentTime = func() time.Time {
+			return time.Now().Add(offset)
+		}
+	}
+	return nil
+}
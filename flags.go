@@ -0,0 +1,164 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setting 可以通过命令行参数和环境变量修改的配置项
+//
+// 环境变量的名称为SPORTX_加上大写的参数名，如--text-live-count对应SPORTX_TEXT_LIVE_COUNT。
+type setting struct {
+	name   string
+	usage  string
+	isBool bool
+	apply  func(c *config, v string) error
+}
+
+var settings = []setting{
+	{name: "category", usage: "启动时选中的分类，ID或者名称", apply: func(c *config, v string) error {
+		c.defaultCategory = v
+		return nil
+	}},
+	{name: "match", usage: "启动时选中的比赛ID，如100000:1471545", apply: func(c *config, v string) error {
+		c.defaultMatch = v
+		return nil
+	}},
+	{name: "refresh", usage: "赛程、统计和文字直播的刷新间隔，如10s", apply: func(c *config, v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		c.scheduleRefreshInterval = d
+		c.statsRefreshInterval = d
+		c.textLiveRefreshInterval = d
+		return nil
+	}},
+	{name: "timeout", usage: "API请求超时时间，如10s", apply: func(c *config, v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		c.apiRequestTimeout = d
+		return nil
+	}},
+	{name: "text-live-count", usage: "每次获取的文字直播数量", apply: func(c *config, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		c.textLiveCount = n
+		return nil
+	}},
+	{name: "no-mouse", usage: "不使用鼠标", isBool: true, apply: func(c *config, v string) error {
+		b, err := strconv.ParseBool(v)
+		c.mouse = !b
+		return err
+	}},
+	{name: "inline", usage: "不使用全屏模式", isBool: true, apply: func(c *config, v string) error {
+		b, err := strconv.ParseBool(v)
+		c.inline = b
+		return err
+	}},
+	{name: "matchweb-url", usage: "matchweb.sports.qq.com 接口地址", apply: func(c *config, v string) error {
+		c.matchWebBaseURL = v
+		return nil
+	}},
+	{name: "app-url", usage: "app.sports.qq.com 接口地址", apply: func(c *config, v string) error {
+		c.appBaseURL = v
+		return nil
+	}},
+	{name: "archive", usage: "把文字直播和统计保存到目录，接口失败时使用保存的内容", apply: func(c *config, v string) error {
+		c.archiveDir = v
+		return nil
+	}},
+	{name: "cache-dir", usage: "缓存接口响应的目录，接口失败时使用，为空时不缓存", apply: func(c *config, v string) error {
+		c.cacheDir = v
+		return nil
+	}},
+	{name: "record", usage: "把接口响应保存到目录", apply: func(c *config, v string) error {
+		c.recordDir = v
+		return nil
+	}},
+	{name: "replay", usage: "从目录读取接口响应，不请求网络", apply: func(c *config, v string) error {
+		c.replayDir = v
+		return nil
+	}},
+}
+
+func (s setting) env() string {
+	return "SPORTX_" + strings.ToUpper(strings.ReplaceAll(s.name, "-", "_"))
+}
+
+// configFlags 注册到FlagSet的配置项，解析参数后调用load合并配置
+type configFlags struct {
+	path   string
+	values []settingValue // 按参数出现的顺序
+}
+
+type settingValue struct {
+	setting setting
+	value   string
+}
+
+func newConfigFlags(fs *flag.FlagSet) *configFlags {
+	f := &configFlags{}
+	fs.StringVar(&f.path, "config", os.Getenv("SPORTX_CONFIG"),
+		"配置文件路径，默认为 "+defaultConfigPath()+" (环境变量 SPORTX_CONFIG)")
+
+	for _, s := range settings {
+		usage := fmt.Sprintf("%s (环境变量 %s)", s.usage, s.env())
+		record := func(v string) error {
+			f.values = append(f.values, settingValue{setting: s, value: v})
+			return nil
+		}
+		if s.isBool {
+			fs.BoolFunc(s.name, usage, record)
+		} else {
+			fs.Func(s.name, usage, record)
+		}
+	}
+	return f
+}
+
+// load 合并配置，优先级：命令行参数 > 环境变量 > 配置文件 > 默认值
+func (f *configFlags) load() error {
+	var err error
+	if f.path != "" {
+		err = cfg.load(f.path, true)
+	} else {
+		err = cfg.load(defaultConfigPath(), false)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, s := range settings {
+		v, ok := os.LookupEnv(s.env())
+		if !ok || v == "" {
+			continue
+		}
+		if err = s.apply(&cfg, v); err != nil {
+			return fmt.Errorf("invalid %s %q: %w", s.env(), v, err)
+		}
+	}
+
+	for _, v := range f.values {
+		if err = v.setting.apply(&cfg, v.value); err != nil {
+			return fmt.Errorf("invalid --%s %q: %w", v.setting.name, v.value, err)
+		}
+	}
+
+	if err = cfg.validate(); err != nil {
+		return err
+	}
+	if err = setupFixtureClock(cfg); err != nil {
+		return err
+	}
+	applyTheme(cfg)
+	return nil
+}
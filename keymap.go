@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// keyMap 可以在配置文件[keybindings]中修改的快捷键
+type keyMap struct {
+	quit      key.Binding
+	nextPanel key.Binding
+	prevPanel key.Binding
+	follow    key.Binding
+	export    key.Binding
+	star      key.Binding
+	debug     key.Binding
+
+	// 赛程面板中只显示某种状态的比赛
+	filterLive     key.Binding
+	filterUpcoming key.Binding
+	filterEnded    key.Binding
+
+	// 赛程面板中按日期跳转
+	today   key.Binding
+	nextDay key.Binding
+	prevDay key.Binding
+
+	// 赛程面板中切换到之后或者之前几天
+	nextRange key.Binding
+	prevRange key.Binding
+
+	// 回放时使用
+	replayPause    key.Binding
+	replayFaster   key.Binding
+	replaySlower   key.Binding
+	replayForward  key.Binding
+	replayBackward key.Binding
+	replayStep     key.Binding
+}
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		quit:      key.NewBinding(key.WithKeys("q", "ctrl+c")),
+		nextPanel: key.NewBinding(key.WithKeys("tab")),
+		prevPanel: key.NewBinding(key.WithKeys("shift+tab")),
+		follow:    key.NewBinding(key.WithKeys("f")),
+		export:    key.NewBinding(key.WithKeys("e")),
+		star:      key.NewBinding(key.WithKeys("s")),
+		debug:     key.NewBinding(key.WithKeys("ctrl+g")),
+
+		filterLive:     key.NewBinding(key.WithKeys("1")),
+		filterUpcoming: key.NewBinding(key.WithKeys("2")),
+		filterEnded:    key.NewBinding(key.WithKeys("3")),
+
+		today:   key.NewBinding(key.WithKeys("t")),
+		nextDay: key.NewBinding(key.WithKeys("n")),
+		prevDay: key.NewBinding(key.WithKeys("p")),
+
+		nextRange: key.NewBinding(key.WithKeys("N")),
+		prevRange: key.NewBinding(key.WithKeys("P")),
+
+		replayPause:    key.NewBinding(key.WithKeys(" ")),
+		replayFaster:   key.NewBinding(key.WithKeys(">")),
+		replaySlower:   key.NewBinding(key.WithKeys("<")),
+		replayForward:  key.NewBinding(key.WithKeys("]")),
+		replayBackward: key.NewBinding(key.WithKeys("[")),
+		replayStep:     key.NewBinding(key.WithKeys(".")),
+	}
+}
+
+func (k *keyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"quit":       &k.quit,
+		"next_panel": &k.nextPanel,
+		"prev_panel": &k.prevPanel,
+		"follow":     &k.follow,
+		"export":     &k.export,
+		"star":       &k.star,
+		"debug":      &k.debug,
+
+		"filter_live":     &k.filterLive,
+		"filter_upcoming": &k.filterUpcoming,
+		"filter_ended":    &k.filterEnded,
+
+		"today":    &k.today,
+		"next_day": &k.nextDay,
+		"prev_day": &k.prevDay,
+
+		"next_range": &k.nextRange,
+		"prev_range": &k.prevRange,
+
+		"replay_pause":    &k.replayPause,
+		"replay_faster":   &k.replayFaster,
+		"replay_slower":   &k.replaySlower,
+		"replay_forward":  &k.replayForward,
+		"replay_backward": &k.replayBackward,
+		"replay_step":     &k.replayStep,
+	}
+}
+
+// bind 按配置修改快捷键，action为快捷键名称
+func (k *keyMap) bind(bindings map[string][]string) error {
+	actions := k.actions()
+	for action, keys := range bindings {
+		b, ok := actions[action]
+		if !ok {
+			names := make([]string, 0, len(actions))
+			for name := range actions {
+				names = append(names, name)
+			}
+			slices.Sort(names)
+			return fmt.Errorf("unknown keybinding %q, available: %s", action, strings.Join(names, ", "))
+		}
+		if len(keys) == 0 {
+			return fmt.Errorf("keybinding %q has no keys", action)
+		}
+		b.SetKeys(keys...)
+	}
+	return nil
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
@@ -21,13 +20,15 @@ func newListPanel(d list.ItemDelegate) listPanel {
 	l.SetShowHelp(false)
 	l.SetShowTitle(false)
 	l.SetShowPagination(false)
+	l.SetFilteringEnabled(false)
 	return listPanel{
 		list:    l,
 		spinner: spinner.New(spinner.WithSpinner(spinner.Dot)),
 	}
 }
 
-func (p listPanel) render(focused bool, status status, err error) string {
+// render labels显示在下边框，如数据的离线状态
+func (p listPanel) render(focused bool, status status, err error, labels ...string) string {
 	style := borderStyle
 	if focused {
 		style = borderFocusedStyle
@@ -44,20 +45,18 @@ func (p listPanel) render(focused bool, status status, err error) string {
 	}
 
 	if status.isFailed() {
-		return centerStyle.Render("加载失败: " + err.Error())
+		return centerStyle.Render(errorText(err))
 	}
 
 	if len(p.list.Items()) == 0 {
-		return centerStyle.Render("暂无数据")
+		return withBorderLabels(centerStyle, p.list.Width(), labels...).Render("暂无数据")
 	}
 
-	indicator := fmt.Sprintf("|%d/%d|", p.list.Index()+1, len(p.list.Items()))
-	content := p.list.View()
-
-	border := style.GetBorderStyle()
-	bottom := strings.Repeat(border.Bottom, max(p.list.Width()-len(indicator)-1, 0))
-	border.Bottom = bottom + indicator + border.Bottom
-	return style.Border(border).Render(content)
+	// 搜索时只计算匹配的条目
+	if n := len(p.list.VisibleItems()); n > 0 {
+		labels = append(labels, fmt.Sprintf("%d/%d", p.list.Index()+1, n))
+	}
+	return withBorderLabels(style, p.list.Width(), labels...).Render(p.list.View())
 }
 
 func (p *listPanel) setSize(width int, height int) {
@@ -1,14 +1,62 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 func main() {
-	p := tea.NewProgram(newApp(), tea.WithAltScreen(), tea.WithMouseCellMotion())
-	if _, err := p.Run(); err != nil {
+	if len(os.Args) > 1 {
+		if c, ok := findCommand(os.Args[1]); ok {
+			os.Exit(runCommand(c, os.Args[2:]))
+		}
+	}
+
+	flags := newConfigFlags(flag.CommandLine)
+	flag.Usage = usage
+	flag.Parse()
+
+	if err := flags.load(); err != nil {
+		exitWithError(err)
+	}
+
+	favs, err := loadFavorites(defaultFavoritesPath())
+	if err != nil {
+		exitWithError(err)
+	}
+
+	if err = runTUI(newApp(newProvider(), favs)); err != nil {
 		os.Exit(1)
 	}
 }
+
+func runTUI(a app) error {
+	opts := []tea.ProgramOption{tea.WithOutput(tuiOutput)}
+	if !cfg.inline {
+		opts = append(opts, tea.WithAltScreen())
+	}
+	if cfg.mouse {
+		opts = append(opts, tea.WithMouseCellMotion())
+	}
+
+	_, err := tea.NewProgram(a, opts...).Run()
+	return err
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "用法: sportx [参数]\n       sportx <命令> [参数]\n\n命令:\n")
+	for _, c := range commands {
+		fmt.Fprintf(out, "  %-12s %s\n", c.name, c.usage)
+	}
+	fmt.Fprintf(out, "\n参数:\n")
+	flag.PrintDefaults()
+}
+
+func exitWithError(err error) {
+	fmt.Fprintln(os.Stderr, "sportx:", err)
+	os.Exit(2) //nolint:mnd // 参数错误
+}
@@ -25,9 +25,15 @@ func (s status) isFailed() bool {
 	return s == statusFailed
 }
 
+// isFinished 请求已经完成，成功或者失败
+func (s status) isFinished() bool {
+	return s.isSuccess() || s.isFailed()
+}
+
 type categoriesMsg struct {
 	categories []category
 	err        error
+	staleness  staleness // 使用离线缓存时的获取时间
 	status
 }
 
@@ -54,9 +60,11 @@ func newCategoriesFailedMsg(err error) categoriesMsg {
 type categorySelectionMsg category
 
 type scheduleMsg struct {
-	category category
-	matches  []match
-	err      error
+	category  category
+	offset    int // 赛程开始日期相对今天的天数
+	matches   []match
+	err       error
+	staleness staleness // 使用离线缓存时的获取时间
 	status
 }
 
@@ -89,13 +97,21 @@ func newScheduleFailedMsg(category category, err error) scheduleMsg {
 	}
 }
 
+// scheduleWindowMsg 赛程面板切换日期范围，offset为开始日期相对今天的天数
+type scheduleWindowMsg struct {
+	category category
+	offset   int
+}
+
 type matchSelectionMsg string
 
 type textLivesMsg struct {
 	matchID   string
 	textLives []textLive
 	hasData   bool
+	hasMore   bool // 是否还有更早的文字直播
 	err       error
+	staleness staleness // 使用离线缓存时的获取时间
 	status
 }
 
@@ -112,11 +128,12 @@ func newTextLivesLoadingMsg(matchID string) textLivesMsg {
 	}
 }
 
-func newTextLivesLoadedMsg(matchID string, textLives []textLive) textLivesMsg {
+func newTextLivesLoadedMsg(matchID string, textLives []textLive, hasMore bool) textLivesMsg {
 	return textLivesMsg{
 		matchID:   matchID,
 		textLives: textLives,
 		hasData:   true,
+		hasMore:   hasMore,
 		status:    statusSuccess,
 	}
 }
@@ -138,10 +155,21 @@ func newTextLivesFailedMsg(matchID string, err error) textLivesMsg {
 	}
 }
 
+// loadOlderTextLivesMsg 请求获取更早的文字直播
+type loadOlderTextLivesMsg string
+
+type olderTextLivesMsg struct {
+	matchID   string
+	textLives []textLive
+	hasMore   bool
+	err       error
+}
+
 type statsMsg struct {
-	matchID string
-	stats   *stats
-	err     error
+	matchID   string
+	stats     *stats
+	err       error
+	staleness staleness // 使用离线缓存时的获取时间
 	status
 }
 
@@ -173,3 +201,20 @@ func newStatsFailedMsg(matchID string, err error) statsMsg {
 		status:  statusFailed,
 	}
 }
+
+// exportTranscriptMsg 导出当前比赛的文字直播
+type exportTranscriptMsg struct {
+	matchID string
+	format  transcriptFormat
+}
+
+type transcriptExportedMsg struct {
+	path string
+	err  error
+}
+
+// favoritesChangedMsg 关注的球队或者分类有变化
+type favoritesChangedMsg struct {
+	text string // 提示的内容
+	err  error  // 保存失败
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// notifyCommandTimeout 通知命令的最长运行时间
+const notifyCommandTimeout = 10 * time.Second
+
+// notifier 比较每次刷新的赛程，找出比分和比赛状态的变化
+type notifier struct {
+	matches map[string]match // 上一次看到的比赛，key为比赛ID
+}
+
+func newNotifier() *notifier {
+	return &notifier{
+		matches: map[string]match{},
+	}
+}
+
+// reset 切换分类或者日期范围后重新开始比较，之前记录的状态已经过时
+func (n *notifier) reset() {
+	clear(n.matches)
+}
+
+// update 记录最新的比赛并返回watched中比赛的变化，第一次看到的比赛不通知
+func (n *notifier) update(matches []match, watched func(match) bool) []string {
+	var changes []string
+	for _, m := range matches {
+		prev, ok := n.matches[m.MID]
+		n.matches[m.MID] = m
+		if !ok || !watched(m) {
+			continue
+		}
+		if text, changed := matchChange(prev, m); changed {
+			changes = append(changes, text)
+		}
+	}
+	return changes
+}
+
+// matchChange 比赛开始、结束或者比分变化时返回通知的内容
+func matchChange(prev, cur match) (string, bool) {
+	switch {
+	case prev.MatchPeriod == periodComing && cur.MatchPeriod == periodInProgress:
+		return "比赛开始 " + matchVersus(cur), true
+	case prev.MatchPeriod != periodEnd && cur.MatchPeriod == periodEnd:
+		return "比赛结束 " + matchVersus(cur), true
+	case prev.LeftGoal != cur.LeftGoal || prev.RightGoal != cur.RightGoal:
+		return "比分变化 " + matchVersus(cur), true
+	}
+	return "", false
+}
+
+// matchVersus 如"湖人 10 - 12 勇士"，未开始时为"湖人 vs 勇士"
+func matchVersus(m match) string {
+	if m.RightName == "" {
+		return m.LeftName
+	}
+	if m.MatchPeriod == periodComing {
+		return fmt.Sprintf("%s vs %s", m.LeftName, m.RightName)
+	}
+	return fmt.Sprintf("%s %s - %s %s", m.LeftName, m.LeftGoal, m.RightGoal, m.RightName)
+}
+
+// tuiOutput 界面的输出，响铃和界面的渲染使用同一个锁，不会写到渲染内容的中间
+var tuiOutput = &terminalOutput{File: os.Stdout}
+
+// terminalOutput 保留*os.File，bubbletea通过Fd判断是否是终端
+type terminalOutput struct {
+	*os.File
+	mu sync.Mutex
+}
+
+func (o *terminalOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.File.Write(p)
+}
+
+func (o *terminalOutput) WriteString(s string) (int, error) {
+	return o.Write([]byte(s))
+}
+
+func (o *terminalOutput) bell() {
+	_, _ = o.WriteString("\a")
+}
+
+// notificationFailedMsg 通知命令运行失败
+type notificationFailedMsg struct {
+	err error
+}
+
+// notify 响铃并运行配置的通知命令，通知的内容作为命令的最后一个参数
+func notify(text string) tea.Cmd {
+	bell, command := cfg.notifyBell, cfg.notifyCommand
+	return func() tea.Msg {
+		if bell {
+			tuiOutput.bell()
+		}
+		if len(command) == 0 {
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), notifyCommandTimeout)
+		defer cancel()
+
+		args := slices.Concat(command[1:], []string{text})
+		//nolint:gosec // 命令来自用户的配置文件
+		out, err := exec.CommandContext(ctx, command[0], args...).CombinedOutput()
+		if err != nil {
+			if msg := strings.TrimSpace(string(out)); msg != "" {
+				err = fmt.Errorf("%w: %s", err, msg)
+			}
+			return notificationFailedMsg{err: err}
+		}
+		return nil
+	}
+}
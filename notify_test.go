@@ -0,0 +1,61 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNotifierUpdate(t *testing.T) {
+	coming := match{MID: "1", LeftName: "湖人", RightName: "勇士", LeftGoal: "0", RightGoal: "0", MatchPeriod: periodComing}
+	started := coming
+	started.MatchPeriod = periodInProgress
+	scored := started
+	scored.LeftGoal = "2"
+	ended := scored
+	ended.MatchPeriod = periodEnd
+
+	all := func(match) bool { return true }
+	none := func(match) bool { return false }
+
+	tests := []struct {
+		name    string
+		prev    []match // 上一次刷新的赛程，为空时是第一次刷新
+		cur     match
+		watched func(match) bool
+		want    []string
+	}{
+		{"first seen", nil, scored, all, nil},
+		{"unchanged", []match{started}, started, all, nil},
+		{"started", []match{coming}, started, all, []string{"比赛开始 湖人 0 - 0 勇士"}},
+		{"scored", []match{started}, scored, all, []string{"比分变化 湖人 2 - 0 勇士"}},
+		{"ended", []match{scored}, ended, all, []string{"比赛结束 湖人 2 - 0 勇士"}},
+		{"ended before seen started", []match{coming}, ended, all, []string{"比赛结束 湖人 2 - 0 勇士"}},
+		{"not watched", []match{started}, scored, none, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newNotifier()
+			if tt.prev != nil {
+				n.update(tt.prev, all)
+			}
+
+			if got := n.update([]match{tt.cur}, tt.watched); !slices.Equal(got, tt.want) {
+				t.Errorf("changes = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifierReset(t *testing.T) {
+	started := match{MID: "1", LeftName: "湖人", RightName: "勇士", LeftGoal: "0", RightGoal: "0", MatchPeriod: periodInProgress}
+	scored := started
+	scored.LeftGoal = "2"
+
+	n := newNotifier()
+	n.update([]match{started}, func(match) bool { return true })
+	// 没有看到的比赛不通知，比较的是之后的刷新
+	n.reset()
+	if got := n.update([]match{scored}, func(match) bool { return true }); got != nil {
+		t.Errorf("changes after reset = %q, want none", got)
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// resource 轮询的数据类型
+type resource string
+
+const (
+	resourceSchedule  resource = "schedule"
+	resourceTextLives resource = "textLives"
+	resourceStats     resource = "stats"
+)
+
+// subscriptionKey 每个(resource, id)最多只有一个轮询
+type subscriptionKey struct {
+	resource resource
+	id       string
+}
+
+func (k subscriptionKey) String() string {
+	return fmt.Sprintf("%s/%s", k.resource, k.id)
+}
+
+// fetcher 请求一次数据并返回对应的消息
+type fetcher func(ctx context.Context) tea.Msg
+
+type subscription struct {
+	key      subscriptionKey
+	interval time.Duration
+	fetch    fetcher
+	cancel   context.CancelFunc
+	ctx      context.Context
+	seq      int       // 只有最近一次调度的pollMsg有效
+	polls    int       // 请求次数
+	fetching bool      // 是否有进行中的请求
+	since    time.Time // 订阅时间
+	next     time.Time // 下次请求时间，零值表示没有等待中的请求
+}
+
+// pollMsg 轮询时间到了
+type pollMsg struct {
+	key subscriptionKey
+	seq int
+}
+
+// poller 统一管理所有的轮询，每个订阅同一时间只有一条刷新链
+type poller struct {
+	subs map[subscriptionKey]*subscription
+	seq  int
+}
+
+func newPoller() *poller {
+	return &poller{
+		subs: map[subscriptionKey]*subscription{},
+	}
+}
+
+// subscribe 订阅key并立即请求一次，同一resource的其它订阅会被取消
+func (p *poller) subscribe(key subscriptionKey, interval time.Duration, fetch fetcher) tea.Cmd {
+	p.unsubscribe(key.resource)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sub := &subscription{
+		key:      key,
+		interval: interval,
+		fetch:    fetch,
+		ctx:      ctx,
+		cancel:   cancel,
+		since:    time.Now(),
+	}
+	p.subs[key] = sub
+
+	return p.run(sub)
+}
+
+// unsubscribe 取消resource的所有订阅以及进行中的请求
+func (p *poller) unsubscribe(r resource) {
+	for k, sub := range p.subs {
+		if k.resource == r {
+			sub.cancel()
+			delete(p.subs, k)
+		}
+	}
+}
+
+// done 收到请求结果，again为true时调度下一次请求，已经取消的订阅不再调度
+func (p *poller) done(key subscriptionKey, again bool) tea.Cmd {
+	sub, ok := p.subs[key]
+	if !ok {
+		return nil
+	}
+
+	sub.fetching = false
+	if !again {
+		sub.next = time.Time{}
+		return nil
+	}
+
+	p.seq++
+	sub.seq = p.seq
+	sub.next = time.Now().Add(sub.interval)
+
+	msg := pollMsg{key: key, seq: sub.seq}
+	return tea.Tick(sub.interval, func(time.Time) tea.Msg {
+		return msg
+	})
+}
+
+// refresh 立即请求所有没有进行中请求的订阅，已停止的订阅也会重新开始
+func (p *poller) refresh() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, sub := range p.subs {
+		if sub.fetching {
+			continue
+		}
+		// 作废等待中的pollMsg
+		p.seq++
+		sub.seq = p.seq
+		sub.next = time.Time{}
+		cmds = append(cmds, p.run(sub))
+	}
+	return tea.Batch(cmds...)
+}
+
+// once 使用订阅的context请求一次，不影响轮询
+func (p *poller) once(key subscriptionKey, fetch fetcher) tea.Cmd {
+	sub, ok := p.subs[key]
+	if !ok {
+		return nil
+	}
+
+	ctx := sub.ctx
+	return func() tea.Msg {
+		if ctx.Err() != nil {
+			return nil
+		}
+		msg := fetch(ctx)
+		// 请求期间订阅被取消，丢弃结果
+		if ctx.Err() != nil {
+			return nil
+		}
+		return msg
+	}
+}
+
+// poll 处理pollMsg，过期的pollMsg直接丢弃
+func (p *poller) poll(msg pollMsg) tea.Cmd {
+	sub, ok := p.subs[msg.key]
+	if !ok || sub.seq != msg.seq {
+		return nil
+	}
+
+	sub.next = time.Time{}
+	return p.run(sub)
+}
+
+func (p *poller) run(sub *subscription) tea.Cmd {
+	sub.polls++
+	sub.fetching = true
+	return p.once(sub.key, sub.fetch)
+}
+
+// subscriptions 当前所有的订阅，用于调试
+func (p *poller) subscriptions() []string {
+	keys := make([]subscriptionKey, 0, len(p.subs))
+	for k := range p.subs {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b subscriptionKey) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	now := time.Now()
+	lines := make([]string, 0, len(keys))
+	for _, k := range keys {
+		sub := p.subs[k]
+		next := "已停止"
+		if sub.fetching {
+			next = "请求中"
+		} else if !sub.next.IsZero() {
+			next = sub.next.Sub(now).Round(time.Second).String()
+		}
+		lines = append(lines, fmt.Sprintf("%s 间隔:%s 次数:%d 下次:%s",
+			k, sub.interval, sub.polls, next))
+	}
+	return lines
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// countingFetcher 返回请求的次数
+func countingFetcher(n *int) fetcher {
+	return func(context.Context) tea.Msg {
+		*n++
+		return *n
+	}
+}
+
+func TestPollerSubscribe(t *testing.T) {
+	p := newPoller()
+	var first, second int
+	key := subscriptionKey{resource: resourceStats, id: "1"}
+	other := subscriptionKey{resource: resourceStats, id: "2"}
+
+	if msg := p.subscribe(key, time.Millisecond, countingFetcher(&first))(); msg != 1 {
+		t.Fatalf("first fetch = %v, want 1", msg)
+	}
+	ctx := p.subs[key].ctx
+
+	// 同一resource只保留最新的订阅
+	p.subscribe(other, time.Millisecond, countingFetcher(&second))
+	if ctx.Err() == nil {
+		t.Error("previous subscription is not canceled")
+	}
+	if cmd := p.done(key, true); cmd != nil {
+		t.Error("done schedules a canceled subscription")
+	}
+	if cmd := p.once(key, countingFetcher(&first)); cmd != nil {
+		t.Error("once fetches a canceled subscription")
+	}
+	if _, ok := p.subs[other]; !ok || len(p.subs) != 1 {
+		t.Errorf("subscriptions = %v, want only %s", p.subscriptions(), other)
+	}
+}
+
+func TestPollerDone(t *testing.T) {
+	tests := []struct {
+		name     string
+		again    bool
+		wantPoll bool
+	}{
+		{"again", true, true},
+		{"stop", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newPoller()
+			var n int
+			key := subscriptionKey{resource: resourceSchedule, id: "1"}
+			p.subscribe(key, time.Millisecond, countingFetcher(&n))
+
+			cmd := p.done(key, tt.again)
+			sub := p.subs[key]
+			if sub.fetching {
+				t.Error("subscription is still fetching")
+			}
+			if got := cmd != nil; got != tt.wantPoll {
+				t.Fatalf("scheduled = %v, want %v", got, tt.wantPoll)
+			}
+			if got := !sub.next.IsZero(); got != tt.wantPoll {
+				t.Errorf("next is set = %v, want %v", got, tt.wantPoll)
+			}
+			if !tt.wantPoll {
+				return
+			}
+
+			msg, ok := cmd().(pollMsg)
+			if !ok || msg.seq != sub.seq {
+				t.Fatalf("poll msg = %v, want seq %d", msg, sub.seq)
+			}
+			if cmd = p.poll(msg); cmd == nil {
+				t.Fatal("current poll msg is dropped")
+			}
+			if got := cmd(); got != 1 {
+				t.Errorf("fetch = %v, want 1", got)
+			}
+		})
+	}
+}
+
+func TestPollerStalePoll(t *testing.T) {
+	p := newPoller()
+	var n int
+	key := subscriptionKey{resource: resourceTextLives, id: "1"}
+	p.subscribe(key, time.Millisecond, countingFetcher(&n))
+
+	// 只有最近一次调度的pollMsg有效
+	stale, _ := p.done(key, true)().(pollMsg)
+	current, _ := p.done(key, true)().(pollMsg)
+	if cmd := p.poll(stale); cmd != nil {
+		t.Error("stale poll msg is not dropped")
+	}
+	if cmd := p.poll(current); cmd == nil {
+		t.Error("current poll msg is dropped")
+	}
+	if cmd := p.poll(pollMsg{key: subscriptionKey{resource: resourceStats, id: "1"}, seq: current.seq}); cmd != nil {
+		t.Error("poll msg of an unknown subscription is not dropped")
+	}
+	if polls := p.subs[key].polls; polls != 2 {
+		t.Errorf("polls = %d, want 2", polls)
+	}
+}
+
+func TestPollerRefresh(t *testing.T) {
+	p := newPoller()
+	var n int
+	key := subscriptionKey{resource: resourceTextLives, id: "1"}
+	p.subscribe(key, time.Millisecond, countingFetcher(&n))
+
+	// 请求进行中时不重复请求
+	if cmd := p.refresh(); cmd != nil {
+		t.Error("refresh fetches a subscription that is fetching")
+	}
+
+	msg, _ := p.done(key, true)().(pollMsg)
+	if cmd := p.refresh(); cmd == nil {
+		t.Fatal("refresh does not fetch an idle subscription")
+	}
+	// refresh之前调度的pollMsg已经过期
+	if cmd := p.poll(msg); cmd != nil {
+		t.Error("stale poll msg is not dropped")
+	}
+
+	// 停止的订阅也会重新开始
+	p.done(key, false)
+	if cmd := p.refresh(); cmd == nil {
+		t.Error("refresh does not restart a stopped subscription")
+	}
+	if polls := p.subs[key].polls; polls != 3 {
+		t.Errorf("polls = %d, want 3", polls)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// newProvider 按配置创建数据源
+func newProvider() provider {
+	var p provider = newTencentProvider(newAPIClient(), cfg.matchWebBaseURL, cfg.appBaseURL)
+	if cfg.archiveDir != "" {
+		p = newArchiveProvider(p, newArchive(cfg.archiveDir))
+	}
+	return p
+}
+
+// provider 赛事数据源
+type provider interface {
+	fetchCategories(ctx context.Context) ([]category, error)
+	fetchSchedule(ctx context.Context, categoryID string, start, end time.Time) ([]match, error)
+	fetchTextLives(ctx context.Context, matchID string, q textLiveQuery) (textLivePage, error)
+	fetchMatchHasTextLives(ctx context.Context, matchID string) (bool, error)
+	fetchStats(ctx context.Context, matchID string) (*stats, error)
+}
+
+// textLiveQuery 增量获取文字直播的条件
+type textLiveQuery struct {
+	known map[string]bool // 已经获取过的文字直播，key为ID
+	older bool            // 为false时只获取最新的textLiveCount条中的，为true时获取更早的
+}
+
+// pick 按ids的顺序返回最多textLiveCount个需要获取的ID，ids最新的在前面
+func (q textLiveQuery) pick(ids []string) []string {
+	if !q.older && len(ids) > cfg.textLiveCount {
+		ids = ids[:cfg.textLiveCount]
+	}
+
+	var ret []string
+	for _, id := range ids {
+		if len(ret) >= cfg.textLiveCount {
+			break
+		}
+		if !q.known[id] {
+			ret = append(ret, id)
+		}
+	}
+	return ret
+}
+
+// textLivePage 增量获取的文字直播
+type textLivePage struct {
+	ids       []string            // 比赛所有文字直播的ID，最新的在前面
+	textLives map[string]textLive // 这次获取的文字直播，key为ID
+	missing   []string            // 需要获取但是没有内容的ID
+}
+
+// newTextLivePage 从all中取出q需要的文字直播
+func newTextLivePage(ids []string, all map[string]textLive, q textLiveQuery) textLivePage {
+	page := textLivePage{ids: ids, textLives: map[string]textLive{}}
+	for _, id := range q.pick(ids) {
+		if v, ok := all[id]; ok {
+			page.textLives[id] = v
+		} else {
+			page.missing = append(page.missing, id)
+		}
+	}
+	return page
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fakeProvider 返回固定的数据，记录每次请求
+type fakeProvider struct {
+	categories   []category
+	schedules    map[string][]match  // key为分类ID
+	ids          []string            // 文字直播的ID列表，最新的在前面
+	texts        map[string]textLive // 有内容的文字直播，key为ID
+	hasTextLives bool
+	stats        *stats
+	err          error // 不为空时所有请求都失败
+
+	mu       sync.Mutex
+	requests []string // 如"schedule 100000"
+}
+
+func (p *fakeProvider) record(request string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.requests = append(p.requests, request)
+	return p.err
+}
+
+// takeRequests 返回并清空已经记录的请求，并发的请求按字母排序
+func (p *fakeProvider) takeRequests() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	requests := p.requests
+	p.requests = nil
+	slices.Sort(requests)
+	return requests
+}
+
+func (p *fakeProvider) fetchCategories(context.Context) ([]category, error) {
+	if err := p.record("categories"); err != nil {
+		return nil, err
+	}
+	return p.categories, nil
+}
+
+func (p *fakeProvider) fetchSchedule(_ context.Context, categoryID string, _, _ time.Time) ([]match, error) {
+	if err := p.record("schedule " + categoryID); err != nil {
+		return nil, err
+	}
+	return p.schedules[categoryID], nil
+}
+
+// fetchTextLives 记录需要获取的ID，如"text lives 10_1,11_1"、"older text lives 12_1"
+func (p *fakeProvider) fetchTextLives(_ context.Context, _ string, q textLiveQuery) (textLivePage, error) {
+	request := "text lives " + strings.Join(q.pick(p.ids), ",")
+	if q.older {
+		request = "older " + request
+	}
+	if err := p.record(strings.TrimSpace(request)); err != nil {
+		return textLivePage{}, err
+	}
+	return newTextLivePage(p.ids, p.texts, q), nil
+}
+
+func (p *fakeProvider) fetchMatchHasTextLives(_ context.Context, matchID string) (bool, error) {
+	if err := p.record("has text lives " + matchID); err != nil {
+		return false, err
+	}
+	return p.hasTextLives, nil
+}
+
+func (p *fakeProvider) fetchStats(_ context.Context, matchID string) (*stats, error) {
+	if err := p.record("stats " + matchID); err != nil {
+		return nil, err
+	}
+	return p.stats, nil
+}
+
+// findMsg 运行cmd并在返回的消息中查找T类型的消息，tea.Batch中的cmd也会运行
+func findMsg[T tea.Msg](cmd tea.Cmd) (T, bool) {
+	var zero T
+	if cmd == nil {
+		return zero, false
+	}
+
+	switch msg := cmd().(type) {
+	case T:
+		return msg, true
+	case tea.BatchMsg:
+		for _, c := range msg {
+			if v, ok := findMsg[T](c); ok {
+				return v, true
+			}
+		}
+	}
+	return zero, false
+}
+
+func TestCategoryPanelUsesProvider(t *testing.T) {
+	nba := category{ID: "100000", Name: "NBA"}
+
+	tests := []struct {
+		name       string
+		err        error
+		want       []category
+		wantFailed bool
+	}{
+		{"loaded", nil, []category{nba}, false},
+		{"failed", errors.New("boom"), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &fakeProvider{categories: []category{nba}, err: tt.err}
+
+			msg, ok := findMsg[categoriesMsg](newCategoryPanel(p, &favorites{}).Init())
+			if !ok {
+				t.Fatal("Init does not fetch categories")
+			}
+			if msg.isFailed() != tt.wantFailed {
+				t.Errorf("failed = %v, want %v", msg.isFailed(), tt.wantFailed)
+			}
+			if !slices.Equal(msg.categories, tt.want) {
+				t.Errorf("categories = %v, want %v", msg.categories, tt.want)
+			}
+			if got := p.takeRequests(); !slices.Equal(got, []string{"categories"}) {
+				t.Errorf("requests = %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// replaySpeeds 回放支持的倍速
+var replaySpeeds = []int{1, 5, 30}
+
+// replaySeekStep 快进快退一次的时间
+const replaySeekStep = time.Minute
+
+// replayClock 回放的虚拟时钟，按倍速从录制的开始时间走到结束时间
+type replayClock struct {
+	mu     sync.Mutex
+	now    func() time.Time
+	start  time.Time   // 录制的开始时间
+	end    time.Time   // 录制的结束时间
+	times  []time.Time // 每条记录的时间，用于单步
+	at     time.Time   // 上次改变状态时的虚拟时间
+	since  time.Time   // 上次改变状态时的真实时间
+	speed  int         // replaySpeeds的下标
+	paused bool
+}
+
+func newReplayClock(records []archiveRecord) *replayClock {
+	times := make([]time.Time, len(records))
+	for i, r := range records {
+		times[i] = r.FetchedAt
+	}
+	slices.SortFunc(times, func(a, b time.Time) int {
+		return a.Compare(b)
+	})
+
+	c := &replayClock{now: time.Now, times: times}
+	if len(times) > 0 {
+		c.start = times[0]
+		c.end = times[len(times)-1]
+	}
+	c.at = c.start
+	c.since = c.now()
+	return c
+}
+
+// current 当前的虚拟时间，调用时需要持有锁
+func (c *replayClock) current() time.Time {
+	if c.paused {
+		return c.at
+	}
+	t := c.at.Add(c.now().Sub(c.since) * time.Duration(replaySpeeds[c.speed]))
+	if t.After(c.end) {
+		return c.end
+	}
+	return t
+}
+
+func (c *replayClock) time() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.current()
+}
+
+// set 从虚拟时间t开始继续计时，调用时需要持有锁
+func (c *replayClock) set(t time.Time) {
+	if t.Before(c.start) {
+		t = c.start
+	}
+	if t.After(c.end) {
+		t = c.end
+	}
+	c.at = t
+	c.since = c.now()
+}
+
+func (c *replayClock) togglePause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.set(c.current())
+	c.paused = !c.paused
+}
+
+// changeSpeed delta为1时加速，为-1时减速
+func (c *replayClock) changeSpeed(delta int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.set(c.current())
+	c.speed = min(max(c.speed+delta, 0), len(replaySpeeds)-1)
+}
+
+func (c *replayClock) seek(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.set(c.current().Add(d))
+}
+
+// step 暂停并跳到下一条记录
+func (c *replayClock) step() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.current()
+	c.paused = true
+	i := slices.IndexFunc(c.times, func(t time.Time) bool {
+		return t.After(now)
+	})
+	if i < 0 {
+		c.set(c.end)
+		return
+	}
+	c.set(c.times[i])
+}
+
+// String 回放状态，如"01-02 20:15:00 / 01-02 22:30:00 ×5 ▶"
+func (c *replayClock) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state := "▶"
+	switch {
+	case c.paused:
+		state = "⏸"
+	case !c.current().Before(c.end):
+		state = "已结束"
+	}
+	return fmt.Sprintf("%s / %s ×%d %s",
+		c.current().Local().Format("01-02 15:04:05"),
+		c.end.Local().Format("01-02 15:04:05"),
+		replaySpeeds[c.speed],
+		state,
+	)
+}
+
+// replayCategory 回放时唯一的分类
+var replayCategory = category{
+	ID:   "replay",
+	Name: "回放",
+}
+
+// replayProvider 按虚拟时间返回录制的内容，只返回虚拟时间之前获取的数据
+type replayProvider struct {
+	matchID string
+	records []archiveRecord
+	clock   *replayClock
+}
+
+func newReplayProvider(matchID string, records []archiveRecord, clock *replayClock) replayProvider {
+	return replayProvider{matchID: matchID, records: records, clock: clock}
+}
+
+// state 虚拟时间之前的内容
+func (p replayProvider) state() *archivedMatch {
+	now := p.clock.time()
+	m := newArchivedMatch()
+	for _, r := range p.records {
+		if !r.FetchedAt.After(now) {
+			m.apply(r)
+		}
+	}
+	return m
+}
+
+// firstMatch 第一条比赛信息，虚拟时间还没到时用来显示赛程
+func (p replayProvider) firstMatch() match {
+	for _, r := range p.records {
+		if r.Kind == archiveMatch && r.Match != nil {
+			return *r.Match
+		}
+	}
+	return match{MID: p.matchID, LeftName: p.matchID}
+}
+
+func (p replayProvider) fetchCategories(context.Context) ([]category, error) {
+	return []category{replayCategory}, nil
+}
+
+func (p replayProvider) fetchSchedule(context.Context, string, time.Time, time.Time) ([]match, error) {
+	m := p.firstMatch()
+	if s := p.state(); s.match != nil {
+		m = *s.match
+	}
+	return []match{m}, nil
+}
+
+func (p replayProvider) fetchMatchHasTextLives(_ context.Context, matchID string) (bool, error) {
+	if matchID != p.matchID {
+		return false, nil
+	}
+	return slices.ContainsFunc(p.records, func(r archiveRecord) bool {
+		return r.Kind == archiveTextLive
+	}), nil
+}
+
+func (p replayProvider) fetchTextLives(_ context.Context, matchID string, q textLiveQuery) (textLivePage, error) {
+	if matchID != p.matchID {
+		return textLivePage{}, nil
+	}
+
+	s := p.state()
+	textLives := s.sortedTextLives()
+	ids := make([]string, len(textLives))
+	for i, v := range textLives {
+		ids[i] = v.IndexValue
+	}
+	return newTextLivePage(ids, s.textLives, q), nil
+}
+
+func (p replayProvider) fetchStats(_ context.Context, matchID string) (*stats, error) {
+	if matchID != p.matchID {
+		return nil, errors.New("match is not in the replay")
+	}
+
+	if s := p.state(); s.stats != nil {
+		return s.stats, nil
+	}
+
+	// 还没有统计时显示空的统计，继续刷新
+	m := p.firstMatch()
+	return &stats{
+		team:        &team{LeftName: m.LeftName, RightName: m.RightName},
+		livePeriod:  periodComing,
+		playerStats: [][]playerStats{},
+	}, nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeReplayClock 录制时间为start、start+1m和start+5m，真实时间由返回的指针控制
+func fakeReplayClock() (*replayClock, *time.Time) {
+	start := time.Date(2025, 1, 2, 20, 0, 0, 0, time.UTC)
+	c := newReplayClock([]archiveRecord{
+		{FetchedAt: start.Add(5 * time.Minute)},
+		{FetchedAt: start},
+		{FetchedAt: start.Add(time.Minute)},
+	})
+	wall := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return wall }
+	c.since = wall
+	return c, &wall
+}
+
+func TestReplayClockSeek(t *testing.T) {
+	tests := []struct {
+		name  string
+		seeks []time.Duration
+		want  time.Duration // 相对录制开始的时间
+	}{
+		{"forward", []time.Duration{2 * time.Minute}, 2 * time.Minute},
+		{"backward", []time.Duration{3 * time.Minute, -time.Minute}, 2 * time.Minute},
+		{"before start", []time.Duration{-time.Minute}, 0},
+		{"after end", []time.Duration{time.Hour}, 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := fakeReplayClock()
+			for _, d := range tt.seeks {
+				c.seek(d)
+			}
+			if got := c.time().Sub(c.start); got != tt.want {
+				t.Errorf("time = start+%s, want start+%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplayClockStep(t *testing.T) {
+	c, _ := fakeReplayClock()
+
+	for _, want := range []time.Duration{time.Minute, 5 * time.Minute, 5 * time.Minute} {
+		c.step()
+		if !c.paused {
+			t.Fatal("step does not pause")
+		}
+		if got := c.time().Sub(c.start); got != want {
+			t.Errorf("time = start+%s, want start+%s", got, want)
+		}
+	}
+}
+
+func TestReplayClockSpeed(t *testing.T) {
+	c, wall := fakeReplayClock()
+
+	c.changeSpeed(1)
+	*wall = wall.Add(10 * time.Second)
+	if got := c.time().Sub(c.start); got != 50*time.Second {
+		t.Errorf("time at ×5 = start+%s, want start+50s", got)
+	}
+
+	// 暂停后时间不变，继续后从暂停的时间开始
+	c.togglePause()
+	*wall = wall.Add(time.Minute)
+	if got := c.time().Sub(c.start); got != 50*time.Second {
+		t.Errorf("paused time = start+%s, want start+50s", got)
+	}
+	c.togglePause()
+	*wall = wall.Add(2 * time.Second)
+	if got := c.time().Sub(c.start); got != time.Minute {
+		t.Errorf("resumed time = start+%s, want start+1m", got)
+	}
+}
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"slices"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
@@ -16,14 +19,31 @@ type schedulePanel struct {
 	msg           scheduleMsg
 	category      category
 	selectedMatch *match
+	pendingMatch  string // 赛程加载后选中的比赛，只在启动时使用
+	favorites     *favorites
+	starring      bool          // 正在选择关注哪只球队
+	period        period        // 只显示这种状态的比赛，为空时显示全部
+	offset        int           // 赛程开始日期相对今天的天数
+	pendingDay    *daySelection // 切换日期范围后要选中的日期
 	listPanel
 }
 
-func newSchedulePanel() schedulePanel {
-	return schedulePanel{
-		msg:       newScheduleInitialMsg(),
-		listPanel: newListPanel(matchDelegate{}),
+// daySelection forward为true时选中day或者之后第一天的比赛，否则选中day或者之前最后一天的比赛
+type daySelection struct {
+	day     string
+	forward bool
+}
+
+func newSchedulePanel(favs *favorites) schedulePanel {
+	s := schedulePanel{
+		msg:          newScheduleInitialMsg(),
+		pendingMatch: cfg.defaultMatch,
+		favorites:    favs,
+		listPanel:    newListPanel(matchDelegate{favorites: favs}),
 	}
+	// 按/搜索，搜索框显示在下边框
+	s.list.SetFilteringEnabled(true)
+	return s
 }
 
 func (s schedulePanel) Init() tea.Cmd {
@@ -44,92 +64,364 @@ func (s schedulePanel) Update(msg tea.Msg) (schedulePanel, tea.Cmd) {
 	case categorySelectionMsg:
 		return s, s.onCategorySelectionMsg(msg)
 	case scheduleMsg:
-		cmd = s.onScheduleMsg(msg)
-		cmds = append(cmds, cmd)
+		cmds = append(cmds, s.onScheduleMsg(msg))
+	case tea.KeyMsg:
+		// 输入搜索内容时所有按键都交给列表
+		if s.list.SettingFilter() {
+			break
+		}
+		if s.starring {
+			return s, s.chooseStarTeam(msg)
+		}
+		switch {
+		case key.Matches(msg, cfg.keys.star):
+			return s, s.startStarring()
+		case key.Matches(msg, cfg.keys.filterLive):
+			return s, s.togglePeriod(periodInProgress)
+		case key.Matches(msg, cfg.keys.filterUpcoming):
+			return s, s.togglePeriod(periodComing)
+		case key.Matches(msg, cfg.keys.filterEnded):
+			return s, s.togglePeriod(periodEnd)
+		case key.Matches(msg, cfg.keys.today):
+			return s, s.gotoToday()
+		case key.Matches(msg, cfg.keys.nextDay):
+			return s, s.moveDay(1)
+		case key.Matches(msg, cfg.keys.prevDay):
+			return s, s.moveDay(-1)
+		case key.Matches(msg, cfg.keys.nextRange):
+			return s, s.moveRange(1)
+		case key.Matches(msg, cfg.keys.prevRange):
+			return s, s.moveRange(-1)
+		}
 	}
 
 	s.list, cmd = s.list.Update(msg)
-	cmds = append(cmds, cmd)
+	cmds = append(cmds, cmd, s.checkSelection())
+
+	return s, tea.Batch(cmds...)
+}
 
+// checkSelection 选中的比赛变化时发送matchSelectionMsg
+func (s *schedulePanel) checkSelection() tea.Cmd {
 	selection, ok := s.list.SelectedItem().(match)
 	if !ok {
+		if s.selectedMatch == nil {
+			return nil
+		}
+		// 过滤或搜索之后没有比赛，清空文字直播和统计
 		s.selectedMatch = nil
-		return s, tea.Batch(cmds...)
+		return func() tea.Msg {
+			return matchSelectionMsg("")
+		}
 	}
 
-	if s.selectedMatch == nil || selection.MID != s.selectedMatch.MID {
-		cmd = func() tea.Msg {
-			return matchSelectionMsg(selection.MID)
+	if s.selectedMatch != nil && selection.MID == s.selectedMatch.MID {
+		return nil
+	}
+	s.selectedMatch = &selection
+	return func() tea.Msg {
+		return matchSelectionMsg(selection.MID)
+	}
+}
+
+// togglePeriod 只显示p状态的比赛，再按一次显示全部
+func (s *schedulePanel) togglePeriod(p period) tea.Cmd {
+	if s.period == p {
+		s.period = ""
+	} else {
+		s.period = p
+	}
+	return tea.Batch(s.setItems(), s.checkSelection())
+}
+
+// setItems 按状态过滤赛程，尽量保持选中的比赛
+func (s *schedulePanel) setItems() tea.Cmd {
+	var items []list.Item
+	for _, m := range s.msg.matches {
+		if s.period == "" || m.MatchPeriod == s.period {
+			items = append(items, m)
 		}
-		cmds = append(cmds, cmd)
-		s.selectedMatch = &selection
 	}
 
-	return s, tea.Batch(cmds...)
+	selected := ""
+	if m, ok := s.list.SelectedItem().(match); ok {
+		selected = m.MID
+	}
+	cmd := s.list.SetItems(items)
+	// 搜索中的结果需要等FilterMatchesMsg更新
+	if s.list.FilterState() == list.Unfiltered {
+		s.list.Select(0)
+		for i, item := range items {
+			if item.(match).MID == selected {
+				s.list.Select(i)
+				break
+			}
+		}
+	}
+	return cmd
 }
 
-func (s *schedulePanel) onCategorySelectionMsg(msg categorySelectionMsg) tea.Cmd {
-	s.category = category(msg)
-	s.list.SetItems([]list.Item{})
+// startStarring 只有一只队伍时直接关注，否则选择关注哪只球队
+func (s *schedulePanel) startStarring() tea.Cmd {
+	m, ok := s.list.SelectedItem().(match)
+	if !ok || m.LeftName == "" {
+		return nil
+	}
+	if m.RightName == "" {
+		starred, err := s.favorites.toggleTeam(m, m.LeftName)
+		return favoritesChanged(m.LeftName, starred, err)
+	}
+	s.starring = true
+	return nil
+}
 
-	var cmds []tea.Cmd
+// chooseStarTeam 按1关注主队，按2关注客队，其他按键取消
+func (s *schedulePanel) chooseStarTeam(msg tea.KeyMsg) tea.Cmd {
+	s.starring = false
+	m, ok := s.list.SelectedItem().(match)
+	if !ok {
+		return nil
+	}
 
-	cmd := func() tea.Msg {
-		return newScheduleLoadingMsg(s.category)
+	var name string
+	switch msg.String() {
+	case "1":
+		name = m.LeftName
+	case "2":
+		name = m.RightName
+	default:
+		return nil
 	}
-	cmds = append(cmds, cmd)
+	starred, err := s.favorites.toggleTeam(m, name)
+	return favoritesChanged(name, starred, err)
+}
 
-	cmd = func() tea.Msg {
-		schedule, err := fetchSchedule(s.category.ID)
-		if err != nil {
-			return newScheduleFailedMsg(s.category, err)
+// moveDay 选中n天后的比赛，超出当前日期范围时获取下一个或者上一个范围的赛程
+func (s *schedulePanel) moveDay(n int) tea.Cmd {
+	m, ok := s.list.SelectedItem().(match)
+	if !ok {
+		return s.shiftWindow(n*scheduleWindow, nil)
+	}
+
+	selection := daySelection{day: addDays(m.day(), n), forward: n > 0}
+	if s.selectDay(selection) {
+		return s.checkSelection()
+	}
+	return s.shiftWindow(n*scheduleWindow, &selection)
+}
+
+// gotoToday 回到默认的日期范围并选中今天的比赛
+func (s *schedulePanel) gotoToday() tea.Cmd {
+	selection := daySelection{day: currentTime().Format(time.DateOnly), forward: true}
+	if s.offset != 0 {
+		return s.shiftWindow(-s.offset, &selection)
+	}
+	s.selectDay(selection)
+	return s.checkSelection()
+}
+
+// moveRange 切换到之后或者之前的日期范围，往前时选中最近一天的比赛
+func (s *schedulePanel) moveRange(n int) tea.Cmd {
+	start := currentTime().AddDate(0, 0, s.offset+n*scheduleWindow)
+	selection := daySelection{day: start.Format(time.DateOnly), forward: true}
+	if n < 0 {
+		selection = daySelection{day: start.AddDate(0, 0, scheduleDays).Format(time.DateOnly)}
+	}
+	return s.shiftWindow(n*scheduleWindow, &selection)
+}
+
+// selectDay 没有符合条件的比赛时返回false
+func (s *schedulePanel) selectDay(selection daySelection) bool {
+	items := s.list.VisibleItems()
+	if selection.forward {
+		for i, item := range items {
+			if item.(match).day() >= selection.day {
+				s.list.Select(i)
+				return true
+			}
+		}
+		return false
+	}
+
+	index := -1
+	for i, item := range items {
+		if item.(match).day() <= selection.day {
+			index = i
 		}
-		return newScheduleLoadedMsg(s.category, schedule)
 	}
-	cmds = append(cmds, cmd)
+	if index < 0 {
+		return false
+	}
+	// 选中这一天的第一场比赛
+	day := items[index].(match).day()
+	for index > 0 && items[index-1].(match).day() == day {
+		index--
+	}
+	s.list.Select(index)
+	return true
+}
 
-	cmds = append(cmds, s.spinner.Tick)
+// shiftWindow 日期范围移动n天并重新获取赛程
+func (s *schedulePanel) shiftWindow(n int, selection *daySelection) tea.Cmd {
+	s.offset += n
+	s.pendingDay = selection
+	s.list.ResetFilter()
+	s.list.SetItems([]list.Item{})
+	s.selectedMatch = nil
+
+	msg := newScheduleLoadingMsg(s.category)
+	msg.offset = s.offset
+	s.onScheduleMsg(msg)
+
+	c, offset := s.category, s.offset
+	return tea.Batch(
+		s.spinner.Tick,
+		func() tea.Msg {
+			return matchSelectionMsg("")
+		},
+		func() tea.Msg {
+			return scheduleWindowMsg{category: c, offset: offset}
+		},
+	)
+}
 
-	cmd = func() tea.Msg {
-		return matchSelectionMsg("")
+// addDays day为2025-01-02格式的日期
+func addDays(day string, n int) string {
+	t, err := time.ParseInLocation(time.DateOnly, day, time.Local)
+	if err != nil {
+		return day
 	}
-	cmds = append(cmds, cmd)
+	return t.AddDate(0, 0, n).Format(time.DateOnly)
+}
 
-	return tea.Batch(cmds...)
+func (s *schedulePanel) onCategorySelectionMsg(msg categorySelectionMsg) tea.Cmd {
+	s.category = category(msg)
+	s.starring = false
+	s.offset = 0
+	s.pendingDay = nil
+	s.list.ResetFilter()
+	s.list.SetItems([]list.Item{})
+
+	s.onScheduleMsg(newScheduleLoadingMsg(s.category))
+
+	return tea.Batch(
+		s.spinner.Tick,
+		func() tea.Msg {
+			return matchSelectionMsg("")
+		},
+	)
 }
 
 func (s *schedulePanel) onScheduleMsg(msg scheduleMsg) tea.Cmd {
-	if !s.category.equal(msg.category) {
+	// 切换日期范围之前发出的请求
+	if !s.category.equal(msg.category) || msg.offset != s.offset {
 		return nil
 	}
 
 	s.msg = msg
-	if msg.isSuccess() {
-		var items []list.Item
-		for _, m := range msg.matches {
-			items = append(items, m)
-		}
-		s.list.SetItems(items)
+	if !msg.isSuccess() {
+		return nil
 	}
 
-	if msg.isSuccess() || msg.isFailed() {
-		return tea.Tick(cfg.scheduleRefreshInterval, func(time.Time) tea.Msg {
-			schedule, err := fetchSchedule(msg.category.ID)
-			if err != nil {
-				return newScheduleFailedMsg(s.category, err)
+	cmd := s.setItems()
+	if s.pendingMatch != "" {
+		for i, item := range s.list.Items() {
+			if item.(match).MID == s.pendingMatch {
+				s.list.Select(i)
+				break
 			}
-			return newScheduleLoadedMsg(s.category, schedule)
-		})
+		}
+		s.pendingMatch = ""
+	}
+	if s.pendingDay != nil {
+		s.selectDay(*s.pendingDay)
+		s.pendingDay = nil
 	}
+	return cmd
+}
 
-	return nil
+// shouldRefresh 今天之前的赛程全部结束后不再刷新
+func (s schedulePanel) shouldRefresh(msg scheduleMsg) bool {
+	if msg.isFailed() {
+		return true
+	}
+
+	if !msg.isSuccess() {
+		return false
+	}
+
+	if msg.offset+scheduleDays >= 0 {
+		return true
+	}
+	return slices.ContainsFunc(msg.matches, func(m match) bool {
+		return m.MatchPeriod != periodEnd
+	})
+}
+
+// scheduleDays 默认获取今天之后几天的赛程
+const scheduleDays = 5
+
+// scheduleWindow 每次获取的赛程包含的天数，包括开始和结束的两天
+const scheduleWindow = scheduleDays + 1
+
+func scheduleKey(c category) subscriptionKey {
+	return subscriptionKey{resource: resourceSchedule, id: c.ID}
+}
+
+// scheduleFetcher 获取今天之后offset天开始的赛程，我的关注合并关注的分类和球队的赛程
+func scheduleFetcher(p provider, favs *favorites, c category, offset int) fetcher {
+	return func(ctx context.Context) tea.Msg {
+		ctx, info := withFetchInfo(ctx)
+		start := currentTime().AddDate(0, 0, offset)
+		end := start.AddDate(0, 0, scheduleDays)
+
+		var schedule []match
+		var err error
+		if c.equal(favoriteCategory) {
+			schedule, err = favs.fetchSchedule(ctx, p, start, end)
+		} else {
+			schedule, err = p.fetchSchedule(ctx, c.ID, start, end)
+		}
+		if err != nil {
+			msg := newScheduleFailedMsg(c, err)
+			msg.offset = offset
+			return msg
+		}
+		msg := newScheduleLoadedMsg(c, schedule)
+		msg.offset = offset
+		msg.staleness = info.get()
+		return msg
+	}
 }
 
 func (s schedulePanel) View(focused bool) string {
-	return s.render(focused, s.msg.status, s.msg.err)
+	if m, ok := s.list.SelectedItem().(match); ok && s.starring {
+		label := fmt.Sprintf("关注 1:%s 2:%s",
+			ansi.Truncate(m.LeftName, 8, "…"),  //nolint:mnd // 边框上的队名最多8个宽度
+			ansi.Truncate(m.RightName, 8, "…"), //nolint:mnd // 边框上的队名最多8个宽度
+		)
+		return s.render(focused, s.msg.status, s.msg.err, label)
+	}
+
+	var window, periodName, filter string
+	if s.offset != 0 {
+		start := currentTime().AddDate(0, 0, s.offset)
+		window = start.Format("01-02") + "~" + start.AddDate(0, 0, scheduleDays).Format("01-02")
+	}
+	if s.period != "" {
+		periodName = s.period.name()
+	}
+	switch s.list.FilterState() {
+	case list.Filtering:
+		filter = "/" + s.list.FilterValue() + "_"
+	case list.FilterApplied:
+		filter = "/" + s.list.FilterValue()
+	}
+	return s.render(focused, s.msg.status, s.msg.err, window, s.msg.staleness.String(), periodName, filter)
 }
 
 type matchDelegate struct {
+	favorites *favorites
 }
 
 func (d matchDelegate) Height() int {
@@ -152,10 +444,17 @@ func (d matchDelegate) Render(w io.Writer, m list.Model, index int, listItem lis
 
 	width := m.Width() - 2 //nolint:mnd // 左右padding
 
+	// 每页第一场和每天第一场比赛上面显示日期
+	header := divider(m.Width())
+	items := m.VisibleItems()
+	if index == m.Paginator.Page*m.Paginator.PerPage || items[index-1].(match).day() != i.day() {
+		header = labeledDivider(m.Width(), dayLabel(i.day(), currentTime()))
+	}
+
 	timeOnly := ""
-	startTime, err := time.Parse("2006-01-02 15:04:05", i.StartTime)
+	startTime, err := time.Parse(time.DateTime, i.StartTime)
 	if err == nil {
-		timeOnly = startTime.Format("01-02 15:04")
+		timeOnly = startTime.Format("15:04")
 	}
 	title := fmt.Sprintf("%s %s", timeOnly, i.MatchDesc)
 	title = ansi.Truncate(title, width, "...")
@@ -164,26 +463,25 @@ func (d matchDelegate) Render(w io.Writer, m list.Model, index int, listItem lis
 		Align(lipgloss.Center).
 		Render(title)
 
-	matchPeriod := "未知"
-	switch i.MatchPeriod {
-	case periodComing:
-		matchPeriod = "未开始"
-	case periodInProgress:
-		matchPeriod = fmt.Sprintf("%s %s", i.Quarter, i.QuarterTime)
-	case periodEnd:
-		matchPeriod = "已结束"
-	}
-	matchPeriod = lipgloss.NewStyle().Width(m.Width()).
+	matchPeriod := lipgloss.NewStyle().Width(m.Width()).
 		Align(lipgloss.Center).
-		Render(matchPeriod)
+		Render(i.periodText())
+
+	leftName, rightName := i.LeftName, i.RightName
+	if d.favorites.isTeam(i, leftName) {
+		leftName = "★" + leftName
+	}
+	if rightName != "" && d.favorites.isTeam(i, rightName) {
+		rightName = "★" + rightName
+	}
 
-	desc := ansi.Truncate(i.LeftName, width, "...")
+	desc := ansi.Truncate(leftName, width, "...")
 	if i.RightName != "" {
 		score := fmt.Sprintf("%s - %s", i.LeftGoal, i.RightGoal)
 		nameWith := (width - 2 - ansi.StringWidth(score)) / 2 //nolint:mnd // 两只队伍各占一半
 
-		leftName := ansi.Truncate(i.LeftName, nameWith, "...")
-		rightName := ansi.Truncate(i.RightName, nameWith, "...")
+		leftName = ansi.Truncate(leftName, nameWith, "...")
+		rightName = ansi.Truncate(rightName, nameWith, "...")
 
 		desc = fmt.Sprintf("%s %s %s",
 			lipgloss.NewStyle().Width(nameWith).Align(lipgloss.Center).Render(leftName),
@@ -201,5 +499,26 @@ func (d matchDelegate) Render(w io.Writer, m list.Model, index int, listItem lis
 
 	content := fmt.Sprintf("%s\n%s\n%s", title, desc, matchPeriod)
 	content = style.Width(m.Width()).Render(content)
-	fmt.Fprint(w, content+"\n"+divider(m.Width()))
+	fmt.Fprint(w, header+"\n"+content)
+}
+
+var weekdayNames = [...]string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"}
+
+// dayLabel 日期的名称，如"今天 01-02"、"周五 01-05"
+func dayLabel(day string, now time.Time) string {
+	t, err := time.ParseInLocation(time.DateOnly, day, time.Local)
+	if err != nil {
+		return day
+	}
+
+	name := weekdayNames[t.Weekday()]
+	switch day {
+	case now.Format(time.DateOnly):
+		name = "今天"
+	case now.AddDate(0, 0, 1).Format(time.DateOnly):
+		name = "明天"
+	case now.AddDate(0, 0, -1).Format(time.DateOnly):
+		name = "昨天"
+	}
+	return name + " " + t.Format("01-02")
 }
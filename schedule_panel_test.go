@@ -0,0 +1,89 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleSelectDay(t *testing.T) {
+	nba := category{ID: "100000", Name: "NBA"}
+	matches := []match{
+		{MID: "1", StartTime: "2025-01-02 09:00:00"},
+		{MID: "2", StartTime: "2025-01-02 10:00:00"},
+		{MID: "3", StartTime: "2025-01-04 09:00:00"},
+		{MID: "4", StartTime: "2025-01-04 10:00:00"},
+	}
+
+	tests := []struct {
+		name      string
+		selection daySelection
+		want      string
+		wantOK    bool
+	}{
+		{"forward same day", daySelection{day: "2025-01-02", forward: true}, "1", true},
+		{"forward skips empty day", daySelection{day: "2025-01-03", forward: true}, "3", true},
+		{"forward after last day", daySelection{day: "2025-01-05", forward: true}, "", false},
+		{"backward same day", daySelection{day: "2025-01-04", forward: false}, "3", true},
+		// 往前选中上一天的第一场比赛
+		{"backward skips empty day", daySelection{day: "2025-01-03", forward: false}, "1", true},
+		{"backward before first day", daySelection{day: "2025-01-01", forward: false}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSchedulePanel(&favorites{})
+			s.category = nba
+			s.onScheduleMsg(newScheduleLoadedMsg(nba, matches))
+			s.list.Select(1)
+
+			if ok := s.selectDay(tt.selection); ok != tt.wantOK {
+				t.Fatalf("selectDay = %v, want %v", ok, tt.wantOK)
+			}
+			if !tt.wantOK {
+				return
+			}
+			if got := s.list.SelectedItem().(match).MID; got != tt.want {
+				t.Errorf("selected %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDayLabel(t *testing.T) {
+	now := time.Date(2025, 1, 2, 13, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		day  string
+		want string
+	}{
+		{"2025-01-01", "昨天 01-01"},
+		{"2025-01-02", "今天 01-02"},
+		{"2025-01-03", "明天 01-03"},
+		{"2025-01-04", "周六 01-04"},
+		{"2024-12-30", "周一 12-30"},
+		{"unknown", "unknown"},
+	}
+	for _, tt := range tests {
+		if got := dayLabel(tt.day, now); got != tt.want {
+			t.Errorf("dayLabel(%s) = %q, want %q", tt.day, got, tt.want)
+		}
+	}
+}
+
+func TestScheduleClearsSelection(t *testing.T) {
+	nba := category{ID: "100000", Name: "NBA"}
+	s := newSchedulePanel(&favorites{})
+	s.category = nba
+	s.onScheduleMsg(newScheduleLoadedMsg(nba, []match{{MID: "1", MatchPeriod: periodInProgress}}))
+	if msg, ok := findMsg[matchSelectionMsg](s.checkSelection()); !ok || msg != "1" {
+		t.Fatalf("selection = %q, %v, want 1", msg, ok)
+	}
+
+	// 只显示已结束的比赛时没有比赛可选
+	msg, ok := findMsg[matchSelectionMsg](s.togglePeriod(periodEnd))
+	if !ok || msg != "" {
+		t.Errorf("selection = %q, %v, want cleared", msg, ok)
+	}
+	if _, ok = findMsg[matchSelectionMsg](s.checkSelection()); ok {
+		t.Error("cleared selection is sent again")
+	}
+}
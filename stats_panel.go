@@ -1,10 +1,10 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/table"
@@ -39,7 +39,6 @@ func (s statsPanel) Init() tea.Cmd {
 
 func (s statsPanel) Update(msg tea.Msg) (statsPanel, tea.Cmd) {
 	var cmd tea.Cmd
-	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
 	case spinner.TickMsg:
@@ -51,59 +50,49 @@ func (s statsPanel) Update(msg tea.Msg) (statsPanel, tea.Cmd) {
 	case matchSelectionMsg:
 		s.matchID = string(msg)
 
+		// 直接设置状态，不经过poller
 		if s.matchID == "" {
-			cmd = func() tea.Msg {
-				return newStatsInitialMsg()
-			}
-			return s, cmd
+			s = s.onStatsMsg(newStatsInitialMsg())
+			return s, nil
 		}
 
-		cmd = func() tea.Msg {
-			return newStatsLoadingMsg(s.matchID)
-		}
-		cmds = append(cmds, cmd)
-
-		cmd = func() tea.Msg {
-			stats, err := fetchStats(s.matchID)
-			if err != nil {
-				return newStatsFailedMsg(s.matchID, err)
-			}
-			return newStatsLoadedMsg(s.matchID, stats)
-		}
-		cmds = append(cmds, cmd)
-
-		cmds = append(cmds, s.spinner.Tick)
-
-		return s, tea.Batch(cmds...)
+		s = s.onStatsMsg(newStatsLoadingMsg(s.matchID))
+		return s, s.spinner.Tick
 	case statsMsg:
-		s, cmd = s.onStatsMsg(msg)
-		return s, cmd
+		s = s.onStatsMsg(msg)
+		return s, nil
 	}
 
 	s.viewport, cmd = s.viewport.Update(msg)
 	return s, cmd
 }
 
-func (s statsPanel) onStatsMsg(msg statsMsg) (statsPanel, tea.Cmd) {
+func (s statsPanel) onStatsMsg(msg statsMsg) statsPanel {
 	if s.matchID != msg.matchID {
-		return s, nil
+		return s
 	}
 	s.msg = msg
 
 	s.updateContent()
 
-	if s.shouldRefresh(msg) {
-		cmd := tea.Tick(cfg.statsRefreshInterval, func(time.Time) tea.Msg {
-			staticstics, err := fetchStats(msg.matchID)
-			if err != nil {
-				return newStatsFailedMsg(msg.matchID, err)
-			}
-			return newStatsLoadedMsg(msg.matchID, staticstics)
-		})
-		return s, cmd
-	}
+	return s
+}
 
-	return s, nil
+func statsKey(matchID string) subscriptionKey {
+	return subscriptionKey{resource: resourceStats, id: matchID}
+}
+
+func statsFetcher(p provider, matchID string) fetcher {
+	return func(ctx context.Context) tea.Msg {
+		ctx, info := withFetchInfo(ctx)
+		staticstics, err := p.fetchStats(ctx, matchID)
+		if err != nil {
+			return newStatsFailedMsg(matchID, err)
+		}
+		msg := newStatsLoadedMsg(matchID, staticstics)
+		msg.staleness = info.get()
+		return msg
+	}
 }
 
 func (s *statsPanel) updateContent() {
@@ -160,7 +149,7 @@ func (s statsPanel) View(focused bool) string {
 	}
 
 	if s.msg.isFailed() {
-		return style.Render("加载失败: " + s.msg.err.Error())
+		return style.Render(errorText(s.msg.err))
 	}
 
 	if s.msg.stats == nil {
@@ -172,7 +161,7 @@ func (s statsPanel) View(focused bool) string {
 		return style.Render("暂无数据")
 	}
 
-	return style.Render(content)
+	return withBorderLabels(style, s.viewport.Width, s.msg.staleness.String()).Render(content)
 }
 
 func (s statsPanel) goalView() string {
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// textLiveFeed 增量获取一场比赛的文字直播
+//
+// 每次刷新只获取还没有获取过的最新内容，更早的内容在需要时通过backfill分批获取。
+type textLiveFeed struct {
+	provider provider
+	matchID  string
+	ended    bool // 选中时比赛已经结束，不会再有新的内容
+
+	mu      sync.Mutex
+	ids     []string            // 最近一次获取的ID列表，最新的在前面
+	entries map[string]textLive // 已获取的文字直播，key为ID
+	skipped map[string]bool     // 没有内容的ID，不再获取
+}
+
+func newTextLiveFeed(p provider, matchID string) *textLiveFeed {
+	return &textLiveFeed{
+		provider: p,
+		matchID:  matchID,
+		entries:  map[string]textLive{},
+		skipped:  map[string]bool{},
+	}
+}
+
+// update 获取新的文字直播并追加到已有的内容中
+func (f *textLiveFeed) update(ctx context.Context) ([]textLive, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// 最新的内容可能还没有详情，下次刷新时重新获取
+	if err := f.fetch(ctx, false); err != nil {
+		return nil, err
+	}
+	return f.textLives(), nil
+}
+
+// backfill 获取下一批更早的文字直播
+func (f *textLiveFeed) backfill(ctx context.Context) ([]textLive, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.hasMissing() {
+		if err := f.fetch(ctx, true); err != nil {
+			return nil, err
+		}
+	}
+	return f.textLives(), nil
+}
+
+// hasMore 是否还有更早的文字直播没有获取
+func (f *textLiveFeed) hasMore() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.hasMissing()
+}
+
+func (f *textLiveFeed) hasMissing() bool {
+	for _, id := range f.ids {
+		if !f.known(id) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *textLiveFeed) known(id string) bool {
+	_, ok := f.entries[id]
+	return ok || f.skipped[id]
+}
+
+// fetch older为true时没有内容的ID不再获取
+func (f *textLiveFeed) fetch(ctx context.Context, older bool) error {
+	known := make(map[string]bool, len(f.entries)+len(f.skipped))
+	for id := range f.entries {
+		known[id] = true
+	}
+	for id := range f.skipped {
+		known[id] = true
+	}
+
+	page, err := f.provider.fetchTextLives(ctx, f.matchID, textLiveQuery{known: known, older: older})
+	if err != nil {
+		return err
+	}
+
+	f.ids = page.ids
+	for id, v := range page.textLives {
+		f.entries[id] = v
+	}
+	if older {
+		for _, id := range page.missing {
+			f.skipped[id] = true
+		}
+	}
+	return nil
+}
+
+// textLives 按ID列表返回已获取的文字直播，不在列表中的已被删除
+func (f *textLiveFeed) textLives() []textLive {
+	var textLives []textLive
+	for _, id := range f.ids {
+		if v, ok := f.entries[id]; ok {
+			textLives = append(textLives, v)
+		}
+	}
+	sortTextLives(textLives)
+	return textLives
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+// fakeTextLives 有内容的文字直播，key为ID
+func fakeTextLives(ids ...string) map[string]textLive {
+	texts := map[string]textLive{}
+	for _, id := range ids {
+		texts[id] = textLive{IndexValue: id}
+	}
+	return texts
+}
+
+func TestTextLiveFeedUpdate(t *testing.T) {
+	prev := cfg.textLiveCount
+	cfg.textLiveCount = 2
+	t.Cleanup(func() { cfg.textLiveCount = prev })
+
+	// ID列表最新的在前面，最新的ID数字最小，排序之后最新的也在前面
+	p := &fakeProvider{
+		ids:   []string{"10_1", "11_1", "12_1", "13_1"},
+		texts: fakeTextLives("9_1", "10_1", "11_1", "12_1", "13_1"),
+	}
+	f := newTextLiveFeed(p, "100000:1")
+
+	steps := []struct {
+		name         string
+		ids          []string // 这一步之前更新的ID列表，为空时不变
+		wantRequests []string
+		want         []string
+	}{
+		{
+			name:         "fetches latest",
+			wantRequests: []string{"text lives 10_1,11_1"},
+			want:         []string{"10_1", "11_1"},
+		},
+		{
+			name:         "skips fetched",
+			wantRequests: []string{"text lives"},
+			want:         []string{"10_1", "11_1"},
+		},
+		{
+			name:         "fetches new",
+			ids:          []string{"9_1", "10_1", "11_1", "12_1", "13_1"},
+			wantRequests: []string{"text lives 9_1"},
+			want:         []string{"9_1", "10_1", "11_1"},
+		},
+		{
+			name:         "drops deleted",
+			ids:          []string{"9_1", "11_1", "12_1", "13_1"},
+			wantRequests: []string{"text lives"},
+			want:         []string{"9_1", "11_1"},
+		},
+	}
+	for _, step := range steps {
+		if step.ids != nil {
+			p.ids = step.ids
+		}
+
+		textLives, err := f.update(context.Background())
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+
+		if got := p.takeRequests(); !slices.Equal(got, step.wantRequests) {
+			t.Errorf("%s: requests = %q, want %q", step.name, got, step.wantRequests)
+		}
+		var got []string
+		for _, v := range textLives {
+			got = append(got, v.IndexValue)
+		}
+		if !slices.Equal(got, step.want) {
+			t.Errorf("%s: text lives = %v, want %v", step.name, got, step.want)
+		}
+	}
+}
+
+func TestTextLiveFeedBackfill(t *testing.T) {
+	prev := cfg.textLiveCount
+	cfg.textLiveCount = 2
+	t.Cleanup(func() { cfg.textLiveCount = prev })
+
+	// 13_1没有内容
+	p := &fakeProvider{
+		ids:   []string{"10_1", "11_1", "12_1", "13_1", "14_1"},
+		texts: fakeTextLives("9_1", "10_1", "11_1", "12_1", "14_1"),
+	}
+	f := newTextLiveFeed(p, "100000:1")
+
+	steps := []struct {
+		name         string
+		run          func(ctx context.Context) ([]textLive, error)
+		ids          []string // 这一步之前更新的ID列表，为空时不变
+		wantRequests []string
+		want         []string
+		wantMore     bool
+	}{
+		{
+			name:         "update fetches latest",
+			run:          f.update,
+			wantRequests: []string{"text lives 10_1,11_1"},
+			want:         []string{"10_1", "11_1"},
+			wantMore:     true,
+		},
+		{
+			name:         "backfill fetches older",
+			run:          f.backfill,
+			wantRequests: []string{"older text lives 12_1,13_1"},
+			want:         []string{"10_1", "11_1", "12_1"},
+			wantMore:     true,
+		},
+		{
+			name:         "backfill skips missing",
+			run:          f.backfill,
+			wantRequests: []string{"older text lives 14_1"},
+			want:         []string{"10_1", "11_1", "12_1", "14_1"},
+		},
+		{
+			name:         "nothing left",
+			run:          f.backfill,
+			wantRequests: nil,
+			want:         []string{"10_1", "11_1", "12_1", "14_1"},
+		},
+		{
+			name:         "update keeps history",
+			run:          f.update,
+			ids:          []string{"9_1", "10_1", "11_1", "12_1", "13_1", "14_1"},
+			wantRequests: []string{"text lives 9_1"},
+			want:         []string{"9_1", "10_1", "11_1", "12_1", "14_1"},
+		},
+	}
+	for _, step := range steps {
+		if step.ids != nil {
+			p.ids = step.ids
+		}
+
+		textLives, err := step.run(context.Background())
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+
+		if got := p.takeRequests(); !slices.Equal(got, step.wantRequests) {
+			t.Errorf("%s: requests = %q, want %q", step.name, got, step.wantRequests)
+		}
+		var got []string
+		for _, v := range textLives {
+			got = append(got, v.IndexValue)
+		}
+		if !slices.Equal(got, step.want) {
+			t.Errorf("%s: text lives = %v, want %v", step.name, got, step.want)
+		}
+		if more := f.hasMore(); more != step.wantMore {
+			t.Errorf("%s: hasMore = %v, want %v", step.name, more, step.wantMore)
+		}
+	}
+}
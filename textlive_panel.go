@@ -1,26 +1,36 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"strings"
-	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
 type textLivePanel struct {
-	spinner spinner.Model
-	matchID string
-	msg     textLivesMsg
-	width   int
-	height  int
+	spinner      spinner.Model
+	viewport     viewport.Model
+	matchID      string
+	msg          textLivesMsg
+	loadingOlder bool  // 正在获取更早的文字直播
+	olderErr     error // 获取更早的文字直播失败
+	follow       bool  // 有新内容时自动滚动到最新
+	unread       int   // 没有跟随最新时收到的新内容数量
+	exporting    bool  // 正在选择导出的格式
+	width        int
+	height       int
 }
 
 func newTextLivePanel(width int) textLivePanel {
 	return textLivePanel{
-		width: width,
+		width:    width,
+		follow:   true,
+		viewport: viewport.New(0, 0),
 		spinner: spinner.New(
 			spinner.WithSpinner(spinner.Dot),
 		),
@@ -46,73 +56,227 @@ func (t textLivePanel) Update(msg tea.Msg) (textLivePanel, tea.Cmd) {
 		cmd = t.onMatchSelectionMsg(msg)
 		return t, cmd
 	case textLivesMsg:
-		cmd = t.onTextLivesMsg(msg)
-		return t, cmd
+		t.onTextLivesMsg(msg)
+		return t, nil
+	case olderTextLivesMsg:
+		t.onOlderTextLivesMsg(msg)
+		return t, nil
+	case tea.KeyMsg:
+		if t.exporting {
+			return t, t.chooseExportFormat(msg)
+		}
+		switch {
+		case key.Matches(msg, cfg.keys.follow):
+			t.toggleFollow()
+			return t, nil
+		case key.Matches(msg, cfg.keys.export):
+			t.exporting = t.msg.isSuccess() && len(t.msg.textLives) > 0
+			return t, nil
+		}
+		return t.scroll(msg)
+	case tea.MouseMsg:
+		return t.scroll(msg)
 	}
 
 	return t, nil
 }
 
+func (t textLivePanel) scroll(msg tea.Msg) (textLivePanel, tea.Cmd) {
+	var cmd tea.Cmd
+
+	offset := t.viewport.YOffset
+	t.viewport, cmd = t.viewport.Update(msg)
+	if t.viewport.YOffset != offset {
+		// 回到最新的内容时自动跟随，离开时暂停
+		t.follow = t.viewport.AtTop()
+		if t.follow {
+			t.unread = 0
+		}
+	}
+
+	return t, tea.Batch(cmd, t.loadOlder())
+}
+
+func (t *textLivePanel) toggleFollow() {
+	t.follow = !t.follow
+	if t.follow {
+		t.unread = 0
+		t.viewport.GotoTop()
+	}
+}
+
+// transcriptFormatKeys 选择导出格式的按键
+var transcriptFormatKeys = map[string]transcriptFormat{
+	"m": transcriptMarkdown,
+	"h": transcriptHTML,
+	"j": transcriptJSON,
+}
+
+// chooseExportFormat 按下格式对应的按键时导出，其他按键取消
+func (t *textLivePanel) chooseExportFormat(msg tea.KeyMsg) tea.Cmd {
+	t.exporting = false
+	format, ok := transcriptFormatKeys[msg.String()]
+	if !ok {
+		return nil
+	}
+
+	matchID := t.matchID
+	return func() tea.Msg {
+		return exportTranscriptMsg{matchID: matchID, format: format}
+	}
+}
+
 func (t *textLivePanel) onMatchSelectionMsg(msg matchSelectionMsg) tea.Cmd {
 	t.matchID = string(msg)
+	t.loadingOlder = false
+	t.olderErr = nil
+	t.follow = true
+	t.unread = 0
+	t.exporting = false
+	t.viewport.GotoTop()
+	// 直接设置状态，不经过poller
 	if t.matchID == "" {
-		return func() tea.Msg {
-			return newTextLivesInitialMsg()
-		}
+		t.onTextLivesMsg(newTextLivesInitialMsg())
+		return nil
 	}
 
-	cmd := func() tea.Msg {
-		return newTextLivesLoadingMsg(t.matchID)
+	t.onTextLivesMsg(newTextLivesLoadingMsg(t.matchID))
+	return t.spinner.Tick
+}
+
+func (t *textLivePanel) onTextLivesMsg(msg textLivesMsg) {
+	if t.matchID != msg.matchID {
+		return
 	}
-	cmds := []tea.Cmd{cmd}
 
-	cmd = func() tea.Msg {
-		hasData, err := fetchMatchHasTextLives(t.matchID)
-		if err != nil {
-			return newTextLivesFailedMsg(t.matchID, err)
-		}
-		if !hasData {
-			return newTextLivesNoDataMsg(t.matchID)
-		}
-		textLives, err := fetchTextLives(t.matchID)
-		if err != nil {
-			return newTextLivesFailedMsg(t.matchID, err)
-		}
-		return newTextLivesLoadedMsg(t.matchID, textLives)
+	// 新的内容在最上面，没有跟随最新时保持当前看到的位置
+	added := 0
+	if msg.isSuccess() && t.msg.isSuccess() {
+		added = max(len(msg.textLives)-len(t.msg.textLives), 0)
 	}
-	cmds = append(cmds, cmd)
+	lines := t.viewport.TotalLineCount()
 
-	cmds = append(cmds, t.spinner.Tick)
+	t.msg = msg
+	t.updateContent()
 
-	return tea.Batch(cmds...)
+	if t.follow {
+		t.viewport.GotoTop()
+		return
+	}
+	t.unread += added
+	t.viewport.SetYOffset(t.viewport.YOffset + t.viewport.TotalLineCount() - lines)
 }
 
-func (t *textLivePanel) onTextLivesMsg(msg textLivesMsg) tea.Cmd {
+func (t *textLivePanel) onOlderTextLivesMsg(msg olderTextLivesMsg) {
 	if t.matchID != msg.matchID {
+		return
+	}
+
+	t.loadingOlder = false
+	t.olderErr = msg.err
+	if msg.err == nil {
+		t.msg.textLives = msg.textLives
+		t.msg.hasMore = msg.hasMore
+	}
+	t.updateContent()
+}
+
+// loadOlder 滚动到最早的内容时获取更早的文字直播
+func (t *textLivePanel) loadOlder() tea.Cmd {
+	if !t.msg.isSuccess() || !t.msg.hasMore || t.loadingOlder || !t.viewport.AtBottom() {
 		return nil
 	}
 
-	t.msg = msg
+	t.loadingOlder = true
+	t.olderErr = nil
+	t.updateContent()
+
+	matchID := t.matchID
+	return func() tea.Msg {
+		return loadOlderTextLivesMsg(matchID)
+	}
+}
+
+func (t *textLivePanel) updateContent() {
+	var lines []string
+	for _, v := range t.msg.textLives {
+		content := v.Content
+		if v.Time != "" {
+			content = fmt.Sprintf("%s %s", v.Time, v.Content)
+		}
+		if v.Plus != "" {
+			plus := fmt.Sprintf("%s(%s-%s)", v.Plus, v.LeftGoal, v.RightGoal)
+			plus = listFocusedStyle.Render(plus)
+			content = fmt.Sprintf("%s %s", content, plus)
+		}
+		if strings.TrimSpace(content) != "" {
+			lines = append(lines, content)
+		}
+	}
+
+	switch {
+	case t.loadingOlder:
+		lines = append(lines, hintStyle.Render("加载更早的内容..."))
+	case t.olderErr != nil:
+		lines = append(lines, hintStyle.Render(errorText(t.olderErr)))
+	case t.msg.hasMore:
+		lines = append(lines, hintStyle.Render("滚动加载更早的内容"))
+	}
 
-	if t.msg.hasData && (msg.isSuccess() || msg.isFailed()) {
-		return tea.Tick(cfg.textLiveRefreshInterval, func(time.Time) tea.Msg {
-			textLives, err := fetchTextLives(msg.matchID)
+	content := lipgloss.NewStyle().Width(t.viewport.Width).Render(strings.Join(lines, "\n"))
+	t.viewport.SetContent(content)
+}
+
+func textLivesKey(matchID string) subscriptionKey {
+	return subscriptionKey{resource: resourceTextLives, id: matchID}
+}
+
+// textLivesFetcher 第一次请求时先检查比赛是否有文字直播，之后增量获取
+func textLivesFetcher(p provider, feed *textLiveFeed) fetcher {
+	matchID := feed.matchID
+	checked := false
+	return func(ctx context.Context) tea.Msg {
+		ctx, info := withFetchInfo(ctx)
+		if !checked {
+			hasData, err := p.fetchMatchHasTextLives(ctx, matchID)
 			if err != nil {
-				return newTextLivesFailedMsg(msg.matchID, err)
+				return newTextLivesFailedMsg(matchID, err)
+			}
+			if !hasData {
+				return newTextLivesNoDataMsg(matchID)
 			}
-			return newTextLivesLoadedMsg(msg.matchID, textLives)
-		})
+			checked = true
+		}
+
+		textLives, err := feed.update(ctx)
+		if err != nil {
+			return newTextLivesFailedMsg(matchID, err)
+		}
+		msg := newTextLivesLoadedMsg(matchID, textLives, feed.hasMore())
+		msg.staleness = info.get()
+		return msg
 	}
+}
 
-	return nil
+// olderTextLivesFetcher 获取下一批更早的文字直播
+func olderTextLivesFetcher(feed *textLiveFeed) fetcher {
+	return func(ctx context.Context) tea.Msg {
+		textLives, err := feed.backfill(ctx)
+		return olderTextLivesMsg{
+			matchID:   feed.matchID,
+			textLives: textLives,
+			hasMore:   feed.hasMore(),
+			err:       err,
+		}
+	}
 }
 
-func (t textLivePanel) View() string {
-	style := lipgloss.NewStyle().
-		Height(t.height).
-		MaxHeight(t.height).
-		Width(t.width).
-		Padding(1, 1)
+func (t textLivePanel) View(focused bool) string {
+	style := borderStyle
+	if focused {
+		style = borderFocusedStyle
+	}
+	style = style.Width(t.width).Height(t.height).Padding(0, 1)
 
 	if t.msg.isInitial() {
 		return style.AlignHorizontal(lipgloss.Center).Render("")
@@ -125,7 +289,7 @@ func (t textLivePanel) View() string {
 
 	if t.msg.isFailed() {
 		return style.AlignHorizontal(lipgloss.Center).
-			Render("加载失败: " + t.msg.err.Error())
+			Render(errorText(t.msg.err))
 	}
 
 	if t.msg.isSuccess() && len(t.msg.textLives) == 0 {
@@ -133,8 +297,6 @@ func (t textLivePanel) View() string {
 			Render("暂无数据")
 	}
 
-	var b strings.Builder
-
 	goal := fmt.Sprintf("%s - %s",
 		t.msg.textLives[0].LeftGoal,
 		t.msg.textLives[0].RightGoal,
@@ -149,26 +311,25 @@ func (t textLivePanel) View() string {
 		Bold(true).
 		Padding(0, 1).
 		Render(goal)
-	b.WriteString(goalView + "\n\n")
 
-	for _, v := range t.msg.textLives {
-		content := v.Content
-		if v.Time != "" {
-			content = fmt.Sprintf("%s %s", v.Time, v.Content)
-		}
-		if v.Plus != "" {
-			plus := fmt.Sprintf("%s(%s-%s)", v.Plus, v.LeftGoal, v.RightGoal)
-			plus = listFocusedStyle.Render(plus)
-			content = fmt.Sprintf("%s %s", content, plus)
-		}
-		if strings.TrimSpace(content) != "" {
-			b.WriteString(content + "\n")
+	indicator := "跟随最新"
+	switch {
+	case t.exporting:
+		indicator = "导出 m:Markdown h:HTML j:JSON"
+	case !t.follow:
+		indicator = "已暂停"
+		if t.unread > 0 {
+			indicator = fmt.Sprintf("↑%d条新内容", t.unread)
 		}
 	}
+	style = withBorderLabels(style, t.width, t.msg.staleness.String(), indicator)
 
-	return style.Render(b.String())
+	return style.Render(goalView + "\n\n" + t.viewport.View())
 }
 
 func (t *textLivePanel) SetHeight(v int) {
 	t.height = v
+	t.viewport.Width = t.width - 2         //nolint:mnd // 左右padding
+	t.viewport.Height = max(t.height-2, 0) //nolint:mnd // 比分和空行
+	t.updateContent()
 }
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 var borderStyle = lipgloss.NewStyle().
@@ -18,10 +20,55 @@ var listFocusedStyle = lipgloss.NewStyle().
 	Foreground(focusedColor).
 	Bold(true)
 
-var focusedColor = lipgloss.AdaptiveColor{Light: "#EE6FF8", Dark: "#EE6FF8"}
+var hintStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.AdaptiveColor{Light: "#A49FA5", Dark: "#777777"})
+
+var focusedColor lipgloss.TerminalColor = lipgloss.AdaptiveColor{Light: "#EE6FF8", Dark: "#EE6FF8"}
+
+// applyTheme 使用配置文件中的颜色
+func applyTheme(c config) {
+	focusedColor = lipgloss.Color(c.focusedColor)
+	borderFocusedStyle = borderFocusedStyle.BorderForeground(focusedColor)
+	listFocusedStyle = listFocusedStyle.Foreground(focusedColor)
+	if c.borderColor != "" {
+		borderStyle = borderStyle.BorderForeground(lipgloss.Color(c.borderColor))
+	}
+}
+
+// withBorderLabels 在下边框右侧显示labels，如"|离线 3分钟前|1/2|"
+//
+// 空的label不显示，宽度不够时从前面开始省略。
+func withBorderLabels(style lipgloss.Style, width int, labels ...string) lipgloss.Style {
+	labels = slices.DeleteFunc(labels, func(s string) bool {
+		return s == ""
+	})
+	label := "|" + strings.Join(labels, "|") + "|"
+	for len(labels) > 0 && ansi.StringWidth(label) >= width {
+		labels = labels[1:]
+		label = "|" + strings.Join(labels, "|") + "|"
+	}
+	if len(labels) == 0 {
+		return style
+	}
+
+	border := style.GetBorderStyle()
+	bottom := strings.Repeat(border.Bottom, max(width-ansi.StringWidth(label)-1, 0))
+	border.Bottom = bottom + label + border.Bottom
+	return style.Border(border)
+}
+
+var dividerStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.AdaptiveColor{Light: "#C2B8C2", Dark: "#4D4D4D"})
 
 func divider(width int) string {
-	s := lipgloss.NewStyle().
-		Foreground(lipgloss.AdaptiveColor{Light: "#C2B8C2", Dark: "#4D4D4D"})
-	return s.Render(strings.Repeat("─", width))
+	return dividerStyle.Render(strings.Repeat("─", width))
+}
+
+// labeledDivider 中间带有label的分隔线，如"─── 今天 01-02 ───"
+func labeledDivider(width int, label string) string {
+	label = " " + ansi.Truncate(label, max(width-2, 0), "") + " " //nolint:mnd // label两边各一个空格
+	n := max(width-ansi.StringWidth(label), 0)
+	left := n / 2 //nolint:mnd // label居中
+	return dividerStyle.Render(strings.Repeat("─", left)) + label +
+		dividerStyle.Render(strings.Repeat("─", n-left))
 }
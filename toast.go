@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const toastDuration = 3 * time.Second
+
+var toastStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("230")).
+	Background(lipgloss.Color("62")).
+	Padding(0, 1)
+
+// toast 在界面最后一行显示的短暂提示
+type toast struct {
+	text string
+	seq  int
+}
+
+// toastExpiredMsg 提示到期，值为显示时的seq
+type toastExpiredMsg int
+
+func (t *toast) show(text string) tea.Cmd {
+	t.seq++
+	t.text = text
+	seq := t.seq
+	return tea.Tick(toastDuration, func(time.Time) tea.Msg {
+		return toastExpiredMsg(seq)
+	})
+}
+
+// expire 只清除同一次显示的提示，之后显示的不受影响
+func (t *toast) expire(msg toastExpiredMsg) {
+	if int(msg) == t.seq {
+		t.text = ""
+	}
+}
+
+// overlay 用提示覆盖view的最后一行
+func (t toast) overlay(view string, width int) string {
+	if t.text == "" {
+		return view
+	}
+
+	lines := strings.Split(view, "\n")
+	text := toastStyle.Render(ansi.Truncate(t.text, max(width-2, 0), "...")) //nolint:mnd // 左右padding
+	lines[len(lines)-1] = lipgloss.PlaceHorizontal(width, lipgloss.Center, text)
+	return strings.Join(lines, "\n")
+}
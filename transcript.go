@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// transcriptFormat 导出文字直播的格式
+type transcriptFormat string
+
+const (
+	transcriptMarkdown transcriptFormat = "markdown"
+	transcriptHTML     transcriptFormat = "html"
+	transcriptJSON     transcriptFormat = "json"
+)
+
+func (f transcriptFormat) ext() string {
+	switch f {
+	case transcriptHTML:
+		return ".html"
+	case transcriptJSON:
+		return ".json"
+	default:
+		return ".md"
+	}
+}
+
+// transcript 一场比赛的文字直播记录，包括比分和统计
+type transcript struct {
+	match      match
+	stats      *stats     // 没有统计时为nil
+	textLives  []textLive // 最新的在前面
+	exportedAt time.Time
+}
+
+func (t transcript) title() string {
+	m := t.match
+	if m.RightName == "" {
+		return m.LeftName
+	}
+	return fmt.Sprintf("%s %s - %s %s", m.LeftName, m.LeftGoal, m.RightGoal, m.RightName)
+}
+
+func (t transcript) subtitle() string {
+	parts := []string{t.match.MatchDesc, t.match.StartTime, t.match.periodText()}
+	parts = slices.DeleteFunc(parts, func(s string) bool {
+		return strings.TrimSpace(s) == ""
+	})
+	return strings.Join(parts, " · ")
+}
+
+// lines 按时间顺序返回文字直播
+func (t transcript) lines() []string {
+	var lines []string
+	for _, v := range slices.Backward(t.textLives) {
+		if line := v.text(); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func (t transcript) tables() []statsTable {
+	if t.stats == nil {
+		return nil
+	}
+	return t.stats.tables()
+}
+
+func (t transcript) render(f transcriptFormat) ([]byte, error) {
+	switch f {
+	case transcriptHTML:
+		return t.html()
+	case transcriptJSON:
+		return t.json()
+	default:
+		return t.markdown(), nil
+	}
+}
+
+func (t transcript) markdown() []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %s\n\n%s\n", t.title(), t.subtitle())
+
+	cell := strings.NewReplacer("|", `\|`, "\n", " ")
+	for _, v := range t.tables() {
+		fmt.Fprintf(&b, "\n## %s\n\n", v.name)
+		for i, row := range append([][]string{v.head}, v.rows...) {
+			cells := make([]string, len(row))
+			for k, c := range row {
+				cells[k] = cell.Replace(c)
+			}
+			fmt.Fprintf(&b, "| %s |\n", strings.Join(cells, " | "))
+			if i == 0 {
+				fmt.Fprintf(&b, "|%s\n", strings.Repeat(" --- |", len(row)))
+			}
+		}
+	}
+
+	b.WriteString("\n## 文字直播\n\n")
+	for _, line := range t.lines() {
+		fmt.Fprintf(&b, "- %s\n", line)
+	}
+
+	fmt.Fprintf(&b, "\n导出时间 %s\n", t.exportedAt.Format(time.DateTime))
+	return b.Bytes()
+}
+
+var transcriptTemplate = template.Must(template.New("transcript").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 48em; margin: 2em auto; padding: 0 1em; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 0.2em 0.6em; text-align: center; }
+li { margin: 0.2em 0; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{.Subtitle}}</p>
+{{range .Tables}}<h2>{{.Name}}</h2>
+<table>
+<tr>{{range .Head}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+{{end}}<h2>文字直播</h2>
+<ul>
+{{range .Lines}}<li>{{.}}</li>
+{{end}}</ul>
+<p>导出时间 {{.ExportedAt}}</p>
+</body>
+</html>
+`))
+
+func (t transcript) html() ([]byte, error) {
+	type table struct {
+		Name string
+		Head []string
+		Rows [][]string
+	}
+
+	var tables []table
+	for _, v := range t.tables() {
+		tables = append(tables, table{Name: v.name, Head: v.head, Rows: v.rows})
+	}
+
+	var b bytes.Buffer
+	err := transcriptTemplate.Execute(&b, struct {
+		Title      string
+		Subtitle   string
+		Tables     []table
+		Lines      []string
+		ExportedAt string
+	}{t.title(), t.subtitle(), tables, t.lines(), t.exportedAt.Format(time.DateTime)})
+	return b.Bytes(), err
+}
+
+func (t transcript) json() ([]byte, error) {
+	textLives := slices.Clone(t.textLives)
+	slices.Reverse(textLives)
+
+	b, err := json.MarshalIndent(struct {
+		Match      match      `json:"match"`
+		Stats      *stats     `json:"stats"`
+		TextLives  []textLive `json:"textLives"`
+		ExportedAt time.Time  `json:"exportedAt"`
+	}{t.match, t.stats, textLives, t.exportedAt}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// exportTranscript 把文字直播写入dir中的文件，返回文件路径
+func exportTranscript(dir string, t transcript, f transcriptFormat) (string, error) {
+	data, err := t.render(f)
+	if err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("sportx-%s-%s%s",
+		strings.ReplaceAll(t.match.MID, ":", "-"),
+		t.exportedAt.Format("20060102-150405"),
+		f.ext(),
+	)
+	path := filepath.Join(dir, name)
+	if err = os.WriteFile(path, data, 0o644); err != nil { //nolint:gosec // 导出的文件需要分享给别人
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testTranscript() transcript {
+	s := testStats()
+	return transcript{
+		match: match{
+			MID: "100000:1471545", LeftName: "湖人", RightName: "勇士", LeftGoal: "100", RightGoal: "98",
+			MatchDesc: "NBA常规赛", StartTime: "2025-01-02 10:30:00", MatchPeriod: periodEnd,
+		},
+		stats: &s,
+		textLives: []textLive{
+			{Time: "00:10", Content: "比赛结束"},
+			{Time: "00:20", Content: "<詹姆斯>上篮", Plus: "+2", LeftGoal: "100", RightGoal: "98"},
+		},
+		exportedAt: time.Date(2025, 1, 2, 13, 0, 0, 0, time.Local),
+	}
+}
+
+func TestTranscriptMarkdown(t *testing.T) {
+	got, err := testTranscript().render(transcriptMarkdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# 湖人 100 - 98 勇士
+
+NBA常规赛 · 2025-01-02 10:30:00 · 已结束
+
+## 比分
+
+|  | 1 | 2 |
+| --- | --- | --- |
+| 湖人 | 20 | 30 |
+| 勇士 | 25 | 28 |
+
+## 球队统计
+
+|  | 湖人 | 勇士 |
+| --- | --- | --- |
+| 篮板 | 40 | 38 |
+
+## 湖人球员统计
+
+| 球员 | 得分 |
+| --- | --- |
+| 詹姆斯 | 30 |
+
+## 勇士球员统计
+
+| 球员 | 得分 |
+| --- | --- |
+| 库里 | 35 |
+
+## 文字直播
+
+- 00:20 <詹姆斯>上篮 +2(100-98)
+- 00:10 比赛结束
+
+导出时间 2025-01-02 13:00:00
+`
+	if string(got) != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTranscriptHTML(t *testing.T) {
+	got, err := testTranscript().render(transcriptHTML)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	html := string(got)
+	for _, want := range []string{
+		"<title>湖人 100 - 98 勇士</title>",
+		"<h2>湖人球员统计</h2>",
+		"<tr><td>篮板</td><td>40</td><td>38</td></tr>",
+		// 文字直播中的内容需要转义
+		"<li>00:20 &lt;詹姆斯&gt;上篮 &#43;2(100-98)</li>\n<li>00:10 比赛结束</li>",
+		"<p>导出时间 2025-01-02 13:00:00</p>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("html does not contain %q", want)
+		}
+	}
+}
+
+func TestTranscriptJSON(t *testing.T) {
+	got, err := testTranscript().render(transcriptJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var v struct {
+		Match     match      `json:"match"`
+		TextLives []textLive `json:"textLives"`
+		Stats     struct {
+			Team team `json:"team"`
+		} `json:"stats"`
+	}
+	if err = json.Unmarshal(got, &v); err != nil {
+		t.Fatal(err)
+	}
+
+	if v.Match.MID != "100000:1471545" || v.Stats.Team.LeftName != "湖人" {
+		t.Errorf("unexpected transcript %s", got)
+	}
+	// 按时间顺序导出
+	if len(v.TextLives) != 2 || v.TextLives[0].Time != "00:20" || v.TextLives[1].Time != "00:10" {
+		t.Errorf("text lives = %+v, want in chronological order", v.TextLives)
+	}
+}
+
+func TestExportTranscript(t *testing.T) {
+	dir := t.TempDir()
+	tr := testTranscript()
+	tr.stats = nil
+
+	path, err := exportTranscript(dir, tr, transcriptMarkdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "sportx-100000-1471545-20250102-130000.md"); path != want {
+		t.Errorf("path = %s, want %s", path, want)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "## 球队统计") {
+		t.Error("transcript without stats should not have stats tables")
+	}
+}
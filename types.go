@@ -1,6 +1,10 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
 	"github.com/charmbracelet/x/ansi"
 )
 
@@ -22,6 +26,19 @@ func (c category) equal(v category) bool {
 	return c.ID == v.ID
 }
 
+// findCategory 按ID或者名称查找分类
+func findCategory(categories []category, v string) (int, bool) {
+	if v == "" {
+		return 0, false
+	}
+	for i, c := range categories {
+		if c.ID == v || c.Name == v {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
 type period string
 
 const (
@@ -30,6 +47,19 @@ const (
 	periodEnd        period = "2"
 )
 
+// name 状态的中文名称
+func (p period) name() string {
+	switch p {
+	case periodComing:
+		return "未开始"
+	case periodInProgress:
+		return "进行中"
+	case periodEnd:
+		return "已结束"
+	}
+	return "未知"
+}
+
 type matchType string
 
 // const (
@@ -59,8 +89,22 @@ func (m match) isMatch() bool {
 		m.MatchDesc != "发布会"
 }
 
+func (m match) periodText() string {
+	if m.MatchPeriod == periodInProgress {
+		return fmt.Sprintf("%s %s", m.Quarter, m.QuarterTime)
+	}
+	return m.MatchPeriod.name()
+}
+
+// day 比赛的日期，如2025-01-02
+func (m match) day() string {
+	day, _, _ := strings.Cut(m.StartTime, " ")
+	return day
+}
+
+// FilterValue 搜索时匹配开始时间、比赛描述和队名
 func (m match) FilterValue() string {
-	return ""
+	return strings.Join([]string{m.StartTime, m.MatchDesc, m.LeftName, m.RightName}, " ")
 }
 
 type textLive struct {
@@ -73,6 +117,18 @@ type textLive struct {
 	Time       string `json:"time"`
 }
 
+// text 不带样式的一行内容，如"12:00 詹姆斯上篮得分 +2(10-8)"
+func (t textLive) text() string {
+	content := strings.TrimSpace(t.Content)
+	if t.Time != "" {
+		content = fmt.Sprintf("%s %s", t.Time, content)
+	}
+	if t.Plus != "" {
+		content = fmt.Sprintf("%s %s(%s-%s)", content, t.Plus, t.LeftGoal, t.RightGoal)
+	}
+	return strings.TrimSpace(content)
+}
+
 func (t textLive) FilterValue() string {
 	return t.Content
 }
@@ -85,11 +141,137 @@ type stats struct {
 	livePeriod  period
 }
 
+// MarshalJSON 导出统计数据，球员统计按球队分组
+func (s stats) MarshalJSON() ([]byte, error) {
+	var t team
+	if s.team != nil {
+		t = *s.team
+	}
+
+	var ps []teamPlayerStats
+	for i, v := range s.playerStats {
+		head, rows := flattenPlayerStats(v)
+		ps = append(ps, teamPlayerStats{Team: t.name(i), Head: head, Rows: rows})
+	}
+
+	return json.Marshal(statsJSON{s.livePeriod, t, s.goal, s.teamStats, ps})
+}
+
+// UnmarshalJSON 读取MarshalJSON导出的统计数据
+func (s *stats) UnmarshalJSON(data []byte) error {
+	var v statsJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	players := make([][]playerStats, len(v.PlayerStats))
+	for i, p := range v.PlayerStats {
+		players[i] = []playerStats{{Head: p.Head}}
+		for _, row := range p.Rows {
+			players[i] = append(players[i], playerStats{Row: row})
+		}
+	}
+
+	*s = stats{
+		team:        &v.Team,
+		goal:        v.Goal,
+		teamStats:   v.TeamStats,
+		livePeriod:  v.LivePeriod,
+		playerStats: players,
+	}
+	return nil
+}
+
+type statsJSON struct {
+	LivePeriod  period            `json:"livePeriod"`
+	Team        team              `json:"team"`
+	Goal        *goalStats        `json:"goal"`
+	TeamStats   []teamStats       `json:"teamStats"`
+	PlayerStats []teamPlayerStats `json:"playerStats"`
+}
+
+type teamPlayerStats struct {
+	Team string     `json:"team"`
+	Head []string   `json:"head"`
+	Rows [][]string `json:"rows"`
+}
+
+// statsTable 导出用的表格
+type statsTable struct {
+	name string
+	head []string
+	rows [][]string
+}
+
+// tables 把比分、球队统计和每只球队的球员统计转换成表格
+func (s stats) tables() []statsTable {
+	var t team
+	if s.team != nil {
+		t = *s.team
+	}
+
+	var tables []statsTable
+	if s.goal != nil {
+		rows := make([][]string, 0, len(s.goal.Rows))
+		for i, v := range s.goal.Rows {
+			rows = append(rows, append([]string{t.name(i)}, v...))
+		}
+		tables = append(tables, statsTable{
+			name: "比分",
+			head: append([]string{""}, s.goal.Head...),
+			rows: rows,
+		})
+	}
+
+	if len(s.teamStats) > 0 {
+		rows := make([][]string, len(s.teamStats))
+		for i, v := range s.teamStats {
+			rows[i] = []string{v.Text, v.LeftVal, v.RightVal}
+		}
+		tables = append(tables, statsTable{
+			name: "球队统计",
+			head: []string{"", t.LeftName, t.RightName},
+			rows: rows,
+		})
+	}
+
+	for i, v := range s.playerStats {
+		head, rows := flattenPlayerStats(v)
+		if len(head) == 0 && len(rows) == 0 {
+			continue
+		}
+		tables = append(tables, statsTable{name: t.name(i) + "球员统计", head: head, rows: rows})
+	}
+
+	return tables
+}
+
+// flattenPlayerStats 返回一只球队的表头和每个球员的数据
+func flattenPlayerStats(s []playerStats) ([]string, [][]string) {
+	var head []string
+	var rows [][]string
+	for _, v := range s {
+		head = append(head, v.Head...)
+		if len(v.Row) > 0 {
+			rows = append(rows, v.Row)
+		}
+	}
+	return head, rows
+}
+
 type team struct {
 	LeftName  string `json:"leftName"`
 	RightName string `json:"rightName"`
 }
 
+// name 按顺序返回主队或者客队的名称
+func (t team) name(i int) string {
+	if i == 0 {
+		return t.LeftName
+	}
+	return t.RightName
+}
+
 func (t team) width() int {
 	left := ansi.StringWidth(t.LeftName)
 	right := ansi.StringWidth(t.RightName)